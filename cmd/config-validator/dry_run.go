@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/fatih/color"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+func parseKeyValue(kv string) (string, string, error) {
+	k, v, ok := strings.Cut(kv, "=")
+	if !ok || k == "" {
+		return "", "", fmt.Errorf("%q must look like key=value", kv)
+	}
+	return k, v, nil
+}
+
+func readMetadata(path string, header http.Header, params map[string]interface{}, changedFiles []string) (*pipelineresolver.Metadata, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read payload: %v", err)
+	}
+	meta := &pipelineresolver.Metadata{
+		Header:     header,
+		Extensions: map[string]interface{}{},
+		Params:     params,
+	}
+	err = json.Unmarshal(buf, &meta.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payload: %v", err)
+	}
+	if len(changedFiles) > 0 {
+		meta.ChangedFiles = changedFiles
+	}
+	return meta, nil
+}
+
+func flagMetadata() (*pipelineresolver.Metadata, error) {
+	header := http.Header{}
+	for _, h := range headers {
+		k, v, err := parseKeyValue(h)
+		if err != nil {
+			return nil, err
+		}
+		header.Add(k, v)
+	}
+	ps := map[string]interface{}{}
+	for _, p := range params {
+		k, v, err := parseKeyValue(p)
+		if err != nil {
+			return nil, err
+		}
+		ps[k] = v
+	}
+	return readMetadata(payload, header, ps, changedFiles)
+}
+
+func newResolverContext() (context.Context, error) {
+	resolver, err := pipelineresolver.NewCelResolver()
+	if err != nil {
+		return nil, err
+	}
+	return pipelineresolver.WithResolver(context.TODO(), resolver), nil
+}
+
+// dryRunPipelineRuns evaluates triggers against a sample payload like pipeline-config-trigger does.
+func dryRunPipelineRuns(cfg *pipelineconfig.Config) ([]*v1.PipelineRun, error) {
+	ctx, err := newResolverContext()
+	if err != nil {
+		return nil, err
+	}
+	// PipelineRuns applies the resolver of the config itself, so it is applied to matching triggers only.
+	matchCtx, err := cfg.ResolverContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := flagMetadata()
+	if err != nil {
+		return nil, err
+	}
+	red := color.New(color.FgHiRed).SprintFunc()
+	green := color.New(color.FgHiGreen).SprintFunc()
+	yellow := color.New(color.FgHiYellow).SprintFunc()
+	for i, t := range cfg.Triggers {
+		ok, err := t.Match(matchCtx, meta)
+		switch {
+		case err != nil:
+			err = locateError(pipelineconfig.ItemError("triggers", i, t.Name, err))
+			log.Printf("Trigger %s: %v. Error: %v", t.Name, red("Failed"), red(err))
+		case ok:
+			log.Printf("Trigger %s: %v", t.Name, green("Matched"))
+		default:
+			log.Printf("Trigger %s: %v", t.Name, yellow("Skipped"))
+		}
+	}
+	return cfg.PipelineRuns(ctx, meta)
+}
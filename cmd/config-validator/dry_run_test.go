@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withConfig(t *testing.T, def, local string) {
+	configDefault, configLocal = def, local
+	t.Cleanup(func() {
+		configDefault, configLocal, payload, changedFiles, update = "", "", "", nil, false
+	})
+}
+
+func TestDryRunPipelineRuns(t *testing.T) {
+	withConfig(t, "testdata/defaults.yaml", "testdata/.tekton.yaml")
+	payload = "testdata/push.json"
+	changedFiles = []string{"services/foo/main.go"}
+	cfg, err := loadConfig()
+	assert.Nil(t, err)
+	prs, err := dryRunPipelineRuns(cfg)
+	assert.Nil(t, err)
+	assert.Len(t, prs, 1)
+	assert.Equal(t, "build-run-", prs[0].GenerateName)
+	assert.Equal(t, "tekton-toolbox", prs[0].Annotations["github.integrations.tekton.dev/repo"])
+	params := map[string]string{}
+	for _, p := range prs[0].Spec.Params {
+		params[p.Name] = p.Value.StringVal
+	}
+	assert.Equal(t, map[string]string{
+		"git-repo-ref":  "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		"git-repo-name": "body.repository.name",
+		"image":         "gcr.io/toolbox/tekton-toolbox",
+		"usage":         "Write expressions as ${{ expr }}",
+	}, params)
+
+	changedFiles = []string{"README.md"}
+	prs, err = dryRunPipelineRuns(cfg)
+	assert.Nil(t, err)
+	assert.Empty(t, prs)
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -21,6 +23,11 @@ var (
 	configLocal      string
 	verbose          bool
 	alphaFeatureGate bool
+	payload          string
+	headers          []string
+	params           []string
+	changedFiles     []string
+	update           bool
 )
 
 func init() {
@@ -29,8 +36,12 @@ func init() {
 	flag.StringVar(&configLocal, "config-local", "", "The path to the local trigger config `.tekton.yaml`.")
 	flag.BoolVar(&verbose, "verbose", false, "Print generated pipelineRuns.")
 	flag.BoolVar(&alphaFeatureGate, "alphaFeatureGate", false, "Config enable-api-fields alpha.")
+	flag.StringVar(&payload, "payload", "", "The path to a sample webhook payload to evaluate triggers against.")
+	pflag.StringArrayVar(&headers, "header", nil, "A header of the sample webhook, eg. X-GitHub-Event=push.")
+	pflag.StringArrayVar(&params, "param", nil, "An interceptor param, eg. owner=body.repository.owner.login.")
+	flag.BoolVar(&update, "update", false, "Update expected PipelineRuns of test cases.")
+	pflag.StringArrayVar(&changedFiles, "changed-file", nil, "A file changed by the sample webhook.")
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
-	pflag.Parse()
 }
 
 func readAndUnmarshalConfig(path string) (*pipelineconfig.Config, error) {
@@ -40,13 +51,40 @@ func readAndUnmarshalConfig(path string) (*pipelineconfig.Config, error) {
 	}
 
 	cfg := &pipelineconfig.Config{}
-	err = yaml.Unmarshal(conf, cfg)
+	err = cfg.UnmarshalYAMLStrict(conf)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal config: %v", sourceError(path, err))
 	}
 	return cfg, nil
 }
 
+// sourceError prefixes a located diagnostic with the path of the config, eg. `.tekton.yaml:3:13: triggers[0].filter`.
+func sourceError(path string, err error) error {
+	var d *pipelineconfig.Diagnostic
+	if errors.As(err, &d) && d.Line > 0 {
+		return fmt.Errorf("%s:%w", path, err)
+	}
+	return err
+}
+
+// locateError finds a diagnostic in the local config first, since it overrides the default one.
+func locateError(err error) error {
+	var d *pipelineconfig.Diagnostic
+	if !errors.As(err, &d) {
+		return err
+	}
+	for _, path := range []string{configLocal, configDefault} {
+		if path == "" {
+			continue
+		}
+		buf, rerr := os.ReadFile(path)
+		if rerr == nil && d.Locate(buf) {
+			return sourceError(path, err)
+		}
+	}
+	return err
+}
+
 func toPipelineRun(p ...*pipelinerun.PipelineRun) (*v1.PipelineRun, error) {
 	tr := &pipelinerun.PipelineRun{}
 	err := tr.MergeAll(p...)
@@ -70,12 +108,7 @@ func PipelineRuns(c pipelineconfig.Config) ([]*v1.PipelineRun, error) {
 	return prs, nil
 }
 
-func processPipelineRuns(cfg *pipelineconfig.Config) ([]byte, bool) {
-	pprs, err := PipelineRuns(*cfg)
-	if err != nil {
-		log.Fatalf("Can't get PPRs: %v", err)
-	}
-
+func processPipelineRuns(pprs []*v1.PipelineRun) ([]byte, bool) {
 	red := color.New(color.FgHiRed).SprintFunc()
 	green := color.New(color.FgHiGreen).SprintFunc()
 
@@ -118,29 +151,77 @@ func enableAlphaAPIFields(ctx context.Context) context.Context {
 	return config.ToContext(ctx, cfg)
 }
 
-func main() {
+func printSchema() {
+	buf, err := json.MarshalIndent(pipelineconfig.JSONSchema(), "", "  ")
+	if err != nil {
+		log.Fatalf("Can't marshal schema: %v", err)
+	}
+	fmt.Println(string(buf))
+}
+
+// loadConfig merges the default config with the local one, if it is set.
+func loadConfig() (*pipelineconfig.Config, error) {
+	cfg, err := readAndUnmarshalConfig(configDefault)
+	if err != nil {
+		return nil, err
+	}
+	if configLocal == "" {
+		return cfg, nil
+	}
+	local, err := readAndUnmarshalConfig(configLocal)
+	if err != nil {
+		return nil, err
+	}
+	err = cfg.Merge(local)
+	if err != nil {
+		return nil, fmt.Errorf("can't merge configs: %v", err)
+	}
+	return cfg, nil
+}
+
+func validate() {
 	if configDefault == "" || configLocal == "" {
 		log.Fatal("Please provide both config-default and config-local")
 	}
-	ppDefConf, err := readAndUnmarshalConfig(configDefault)
+	ppDefConf, err := loadConfig()
 	if err != nil {
 		log.Fatal(err)
 	}
-	ppRepConf, err := readAndUnmarshalConfig(configLocal)
+	err = ppDefConf.Validate()
 	if err != nil {
-		log.Fatal(err)
+		log.Fatalf("Invalid config: %v", locateError(err))
 	}
 
-	err = ppDefConf.Merge(ppRepConf)
+	var pprs []*v1.PipelineRun
+	if payload != "" {
+		pprs, err = dryRunPipelineRuns(ppDefConf)
+	} else {
+		pprs, err = PipelineRuns(*ppDefConf)
+	}
 	if err != nil {
-		log.Fatalf("Can't merge configs: %v", err)
+		log.Fatalf("Can't get PPRs: %v", locateError(err))
 	}
-
-	ppr, fail := processPipelineRuns(ppDefConf)
-	if verbose {
+	ppr, fail := processPipelineRuns(pprs)
+	if verbose || payload != "" {
 		fmt.Printf("---\n%s", string(ppr))
 	}
 	if !fail {
 		log.Fatal("Pipelines validation failed.")
 	}
 }
+
+func main() {
+	pflag.Parse()
+	switch pflag.Arg(0) {
+	case "":
+		validate()
+	case "schema":
+		printSchema()
+	case "test":
+		test(pflag.Arg(1))
+	case "migrate":
+		migrate()
+	default:
+		log.Fatalf("Unknown command %q", pflag.Arg(0))
+	}
+}
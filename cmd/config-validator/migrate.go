@@ -0,0 +1,247 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelinerun"
+	"github.com/fatih/color"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+// configValue is a value which is resolved by SafeValueOf, and err holds its path in a config.
+type configValue struct {
+	value string
+	err   func(error) error
+}
+
+func fieldError(keys ...string) func(error) error {
+	return func(err error) error {
+		for i := len(keys) - 1; i >= 0; i-- {
+			err = pipelineconfig.FieldError(keys[i], err)
+		}
+		return err
+	}
+}
+
+func within(outer func(error) error, inner func(error) error) func(error) error {
+	return func(err error) error {
+		return outer(inner(err))
+	}
+}
+
+func mapValues(m map[string]string, path func(error) error, field string) []configValue {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var vals []configValue
+	for _, k := range keys {
+		vals = append(vals, configValue{value: m[k], err: within(path, fieldError(field, k))})
+	}
+	return vals
+}
+
+// stringValues returns non-empty values of fields, eg. `metadata.namespace`.
+func stringValues(path func(error) error, fields map[string]string) []configValue {
+	keys := make([]string, 0, len(fields))
+	for k, v := range fields {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var vals []configValue
+	for _, k := range keys {
+		vals = append(vals, configValue{value: fields[k], err: within(path, fieldError(strings.Split(k, ".")...))})
+	}
+	return vals
+}
+
+func specValues(p *pipelinerun.PipelineRun, path func(error) error) []configValue {
+	fields := map[string]string{
+		"metadata.namespace":                 p.Namespace,
+		"metadata.generateName":              p.GenerateName,
+		"taskRunTemplate.serviceAccountName": p.TaskRunTemplate.ServiceAccountName,
+	}
+	if p.PipelineRef != nil {
+		fields["pipelineRef.name"] = p.PipelineRef.Name
+	}
+	if p.Timeouts != nil {
+		fields["timeouts.pipeline"] = p.Timeouts.Pipeline
+		fields["timeouts.tasks"] = p.Timeouts.Tasks
+		fields["timeouts.finally"] = p.Timeouts.Finally
+	}
+	vals := stringValues(path, fields)
+	if t := p.TaskRunTemplate.PodTemplate; t != nil {
+		vals = append(vals, mapValues(t.NodeSelector, within(path, fieldError("taskRunTemplate", "podTemplate")), "nodeSelector")...)
+	}
+	for i, s := range p.TaskRunSpecs {
+		item := func(err error) error {
+			return path(pipelineconfig.ItemError("taskRunSpecs", i, "", err))
+		}
+		vals = append(vals, stringValues(item, map[string]string{"serviceAccountName": s.ServiceAccountName})...)
+		if s.PodTemplate != nil {
+			vals = append(vals, mapValues(s.PodTemplate.NodeSelector, within(item, fieldError("podTemplate")), "nodeSelector")...)
+		}
+	}
+	for i, w := range p.Workspaces {
+		item := func(err error) error {
+			return path(pipelineconfig.ItemError("workspaces", i, w.Name, err))
+		}
+		vals = append(vals, stringValues(item, map[string]string{"subPath": w.SubPath})...)
+	}
+	return vals
+}
+
+func pipelineRunValues(p *pipelinerun.PipelineRun, path func(error) error) []configValue {
+	vals := mapValues(p.Labels, within(path, fieldError("metadata")), "labels")
+	vals = append(vals, mapValues(p.Annotations, within(path, fieldError("metadata")), "annotations")...)
+	vals = append(vals, specValues(p, path)...)
+	for i, param := range p.Params {
+		item := func(err error) error {
+			return path(pipelineconfig.ItemError("params", i, param.Name, pipelineconfig.FieldError("value", err)))
+		}
+		switch param.Value.Type {
+		case v1.ParamTypeString:
+			vals = append(vals, configValue{value: param.Value.StringVal, err: item})
+		case v1.ParamTypeArray:
+			for _, v := range param.Value.ArrayVal {
+				vals = append(vals, configValue{value: v, err: item})
+			}
+		case v1.ParamTypeObject:
+			keys := make([]string, 0, len(param.Value.ObjectVal))
+			for k := range param.Value.ObjectVal {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				vals = append(vals, configValue{value: param.Value.ObjectVal[k], err: within(item, fieldError(k))})
+			}
+		}
+	}
+	return vals
+}
+
+func configValues(cfg *pipelineconfig.Config) []configValue {
+	vals := pipelineRunValues(&cfg.Defaults, fieldError("defaults"))
+	for i, t := range cfg.Triggers {
+		trigger := func(err error) error {
+			return pipelineconfig.ItemError("triggers", i, t.Name, err)
+		}
+		vals = append(vals, pipelineRunValues(&t.Defaults, within(trigger, fieldError("defaults")))...)
+		for j, p := range t.Pipelines {
+			pipeline := func(err error) error {
+				return trigger(pipelineconfig.ItemError("pipelines", j, p.Name, err))
+			}
+			vals = append(vals, pipelineRunValues(&p, pipeline)...)
+		}
+	}
+	return vals
+}
+
+// looksLikeExpr reports whether an expression selects a field or calls a function, unlike words or names with
+// dashes, eg. `main` or `pipeline-1`, which parse as expressions too.
+func looksLikeExpr(a *cel.Ast) bool {
+	var found bool
+	ast.PreOrderVisit(a.NativeRep().Expr(), ast.NewExprVisitor(func(e ast.Expr) {
+		switch e.Kind() {
+		case ast.SelectKind:
+			found = true
+		case ast.CallKind:
+			if _, ok := operators.FindReverse(e.AsCall().FunctionName()); !ok {
+				found = true
+			}
+		}
+	}))
+	return found
+}
+
+// migrateValue returns an error for a value which is resolved as an expression, or which is ambiguous, since it is
+// used as a literal under the implicit mode, but looks like a broken expression.
+func migrateValue(env *cel.Env, val string) (error, bool) {
+	a, issues := env.Parse(val)
+	if issues != nil && issues.Err() != nil {
+		return nil, false
+	}
+	_, issues = env.Check(a)
+	if issues == nil || issues.Err() == nil {
+		return fmt.Errorf("expression, write it as `${{ %s }}`", val), false
+	}
+	if looksLikeExpr(a) {
+		return fmt.Errorf("used as a literal, but looks like an expression: %v", issues.Err()), true
+	}
+	return nil, false
+}
+
+func migrateConfig(env *cel.Env, path string) int {
+	red := color.New(color.FgHiRed).SprintFunc()
+	yellow := color.New(color.FgHiYellow).SprintFunc()
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Can't read config: %v", err)
+	}
+	cfg, err := readAndUnmarshalConfig(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cfg.Expressions == pipelineconfig.ExpressionsExplicit {
+		log.Printf("Config %s already uses explicit expressions", path)
+		return 0
+	}
+	if cfg.Resolver == pipelineconfig.ResolverTemplate {
+		log.Printf("Config %s uses templates instead of CEL expressions", path)
+		return 0
+	}
+	var ambiguous int
+	for _, v := range configValues(cfg) {
+		verr, ok := migrateValue(env, v.value)
+		if verr == nil {
+			continue
+		}
+		err := v.err(verr)
+		var d *pipelineconfig.Diagnostic
+		if errors.As(err, &d) {
+			d.Locate(buf)
+		}
+		err = sourceError(path, err)
+		if ok {
+			ambiguous++
+			log.Printf("%v: %v", red("Ambiguous"), err)
+		} else {
+			log.Printf("%v: %v", yellow("Expression"), err)
+		}
+	}
+	return ambiguous
+}
+
+// migrate lists values which have to be changed to use `expressions: explicit`, and fails if some of them are
+// ambiguous under the implicit mode.
+func migrate() {
+	if configDefault == "" && configLocal == "" {
+		log.Fatal("Please provide config-default or config-local")
+	}
+	env, err := pipelineresolver.NewCelEnv()
+	if err != nil {
+		log.Fatalf("Can't create CEL environment: %v", err)
+	}
+	var ambiguous int
+	for _, path := range []string{configDefault, configLocal} {
+		if path != "" {
+			ambiguous += migrateConfig(env, path)
+		}
+	}
+	if ambiguous > 0 {
+		log.Fatalf("%d values are ambiguous.", ambiguous)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateConfig(t *testing.T) {
+	env, err := pipelineresolver.NewCelEnv()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, migrateConfig(env, "testdata/migrate.yaml"))
+	assert.Equal(t, 0, migrateConfig(env, "testdata/defaults.yaml"))
+}
+
+func TestMigrateValue(t *testing.T) {
+	env, err := pipelineresolver.NewCelEnv()
+	assert.Nil(t, err)
+	tests := []struct {
+		value     string
+		err       bool
+		ambiguous bool
+	}{
+		{value: "main"},
+		{value: "pipeline-1"},
+		{value: "https://tekton.dev/#/namespaces"},
+		{value: "body.after", err: true},
+		{value: "bdy.repository.name", err: true, ambiguous: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			err, ambiguous := migrateValue(env, tt.value)
+			assert.Equal(t, tt.err, err != nil)
+			assert.Equal(t, tt.ambiguous, ambiguous)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/pmezard/go-difflib/difflib"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	testPayloadFile  = "payload.json"
+	testRequestFile  = "request.yaml"
+	testExpectedFile = "expected.yaml"
+)
+
+// testRequest holds everything of a webhook request, except its payload, which is kept as is in payload.json.
+type testRequest struct {
+	Headers      map[string]string       `json:"headers,omitempty"`
+	Params       map[string]string       `json:"params,omitempty"`
+	ChangedFiles []string                `json:"changedFiles,omitempty"`
+	Context      *v1beta1.TriggerContext `json:"context,omitempty"`
+}
+
+func readTestRequest(dir string) (*testRequest, error) {
+	req := &testRequest{}
+	buf, err := os.ReadFile(filepath.Join(dir, testRequestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return req, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.UnmarshalStrict(buf, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %v", testRequestFile, err)
+	}
+	return req, nil
+}
+
+func marshalPipelineRuns(prs []*v1.PipelineRun) ([]byte, error) {
+	var out []byte
+	for _, pr := range prs {
+		buf, err := yaml.Marshal(pr)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, "---\n"...)
+		out = append(out, buf...)
+	}
+	return out, nil
+}
+
+// runTestCase returns PipelineRuns generated for a test case in dir as YAML.
+func runTestCase(dir string) ([]byte, error) {
+	req, err := readTestRequest(dir)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	for k, v := range req.Headers {
+		header.Add(k, v)
+	}
+	ps := map[string]interface{}{}
+	for k, v := range req.Params {
+		ps[k] = v
+	}
+	meta, err := readMetadata(filepath.Join(dir, testPayloadFile), header, ps, req.ChangedFiles)
+	if err != nil {
+		return nil, err
+	}
+	if req.Context != nil {
+		meta.RequestURL = req.Context.EventURL
+		meta.TriggerContext = req.Context
+	}
+	// Config is loaded for every test case, since generating PipelineRuns may modify it.
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := newResolverContext()
+	if err != nil {
+		return nil, err
+	}
+	prs, err := cfg.PipelineRuns(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+	return marshalPipelineRuns(prs)
+}
+
+func diffTestCase(expected, actual []byte) string {
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expected)),
+		B:        difflib.SplitLines(string(actual)),
+		FromFile: "expected",
+		ToFile:   "actual",
+		Context:  3,
+	})
+	return diff
+}
+
+// test runs every test case in subdirectories of dir, and compares generated PipelineRuns with expected.yaml, or
+// updates it.
+func test(dir string) {
+	if dir == "" {
+		log.Fatal("Please provide a directory of test cases")
+	}
+	if configDefault == "" {
+		log.Fatal("Please provide config-default")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Fatalf("Can't read test cases: %v", err)
+	}
+	red := color.New(color.FgHiRed).SprintFunc()
+	green := color.New(color.FgHiGreen).SprintFunc()
+	yellow := color.New(color.FgHiYellow).SprintFunc()
+
+	var failed int
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		caseDir := filepath.Join(dir, e.Name())
+		actual, err := runTestCase(caseDir)
+		if err != nil {
+			err = locateError(err)
+			log.Printf("Test %s: %v. Error: %v", e.Name(), red("Failed"), red(err))
+			failed++
+			continue
+		}
+		expectedPath := filepath.Join(caseDir, testExpectedFile)
+		if update {
+			err = os.WriteFile(expectedPath, actual, 0o644)
+			if err != nil {
+				log.Fatalf("Can't update %s: %v", expectedPath, err)
+			}
+			log.Printf("Test %s: %v", e.Name(), yellow("Updated"))
+			continue
+		}
+		expected, err := os.ReadFile(expectedPath)
+		if err != nil {
+			log.Printf("Test %s: %v. Error: %v", e.Name(), red("Failed"), red(err))
+			failed++
+			continue
+		}
+		if string(expected) != string(actual) {
+			log.Printf("Test %s: %v\n%s", e.Name(), red("Failed"), diffTestCase(expected, actual))
+			failed++
+			continue
+		}
+		log.Printf("Test %s: %v", e.Name(), green("Passed"))
+	}
+	if failed > 0 {
+		log.Fatalf("%d test cases failed.", failed)
+	}
+}
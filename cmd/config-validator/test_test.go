@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunTestCase(t *testing.T) {
+	withConfig(t, "testdata/defaults.yaml", "testdata/.tekton.yaml")
+	for _, name := range []string{"push-main", "pull-request"} {
+		t.Run(name, func(t *testing.T) {
+			dir := filepath.Join("testdata", "tests", name)
+			actual, err := runTestCase(dir)
+			assert.Nil(t, err)
+			expected, err := os.ReadFile(filepath.Join(dir, testExpectedFile))
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), string(actual))
+		})
+	}
+}
+
+func TestTest_Update(t *testing.T) {
+	withConfig(t, "testdata/defaults.yaml", "testdata/.tekton.yaml")
+	update = true
+	dir := t.TempDir()
+	for _, name := range []string{"push-main", "pull-request"} {
+		assert.Nil(t, os.Mkdir(filepath.Join(dir, name), 0o755))
+		buf, err := os.ReadFile(filepath.Join("testdata", "tests", name, testPayloadFile))
+		assert.Nil(t, err)
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name, testPayloadFile), buf, 0o644))
+	}
+	buf, err := os.ReadFile(filepath.Join("testdata", "tests", "push-main", testRequestFile))
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "push-main", testRequestFile), buf, 0o644))
+	test(dir)
+	for _, name := range []string{"push-main", "pull-request"} {
+		expected, err := os.ReadFile(filepath.Join("testdata", "tests", name, testExpectedFile))
+		assert.Nil(t, err)
+		actual, err := os.ReadFile(filepath.Join(dir, name, testExpectedFile))
+		assert.Nil(t, err)
+		assert.Equal(t, string(expected), string(actual), name)
+	}
+}
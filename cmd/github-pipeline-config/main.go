@@ -16,6 +16,9 @@ import (
 	"github.com/ElementalCognition/tekton-toolbox/internal/viperconfig"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/githubpipelineconfig"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/githubtransport"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/kubepipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfigreporter"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/triggers"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig"
@@ -24,6 +27,7 @@ import (
 	"github.com/google/go-github/v43/github"
 	"github.com/spf13/pflag"
 	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"knative.dev/pkg/injection"
 	"knative.dev/pkg/logging"
@@ -32,9 +36,14 @@ import (
 
 type config struct {
 	Addr                 string
-	GithubAppID          int64  `mapstructure:"github-app-id"`
-	GithubInstallationID int64  `mapstructure:"github-installation-id"`
-	GithubAppKey         string `mapstructure:"github-app-key"`
+	GithubAppID          int64         `mapstructure:"github-app-id"`
+	GithubInstallationID int64         `mapstructure:"github-installation-id"`
+	GithubAppKey         string        `mapstructure:"github-app-key"`
+	CacheSize            int           `mapstructure:"cache-size"`
+	CacheTTL             time.Duration `mapstructure:"cache-ttl"`
+	ReportErrors         bool          `mapstructure:"report-errors"`
+	IncludeNamespaces    []string      `mapstructure:"include-namespaces"`
+	IncludeOwners        []string      `mapstructure:"include-owners"`
 }
 
 const (
@@ -51,6 +60,11 @@ func init() {
 	flag.Int64("github-app-id", 0, "GitHub App ID.")
 	flag.Int64("github-installation-id", 0, "GitHub Installation ID.")
 	flag.String("github-app-key", "", "GitHub App key.")
+	flag.Int("cache-size", 1000, "The maximum number of cached configs, zero disables the cache.")
+	flag.Duration("cache-ttl", 0, "The time to keep a cached config, zero keeps it until evicted.")
+	flag.Bool("report-errors", false, "Report config errors as check runs.")
+	flag.String("include-namespaces", "", "Comma-separated namespaces of ConfigMaps which configs may include, defaults to the interceptor namespace.")
+	flag.String("include-owners", "", "Comma-separated owners of repositories which configs may include.")
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	pflag.Parse()
 }
@@ -66,11 +80,14 @@ func newGithubClient(cfg *config) (*github.Client, error) {
 func newMux(
 	service vcspipelineconfig.Service,
 	resolver pipelineresolver.Resolver,
+	includes *pipelineconfig.IncludeResolver,
+	reporter pipelineconfigreporter.Reporter,
 	logger *zap.SugaredLogger,
 ) *chi.Mux {
 	mux := chi.NewRouter()
 	mux.Group(func(r chi.Router) {
 		chimiddleware.WithHeartbeat(r)
+		chimiddleware.WithMetrics(r)
 	})
 	mux.Group(func(r chi.Router) {
 		r.Use(middleware.RequestID)
@@ -81,6 +98,8 @@ func newMux(
 			vcspipelineconfig.NewInterceptor(
 				service,
 				resolver,
+				includes,
+				reporter,
 			),
 		))
 	})
@@ -119,11 +138,38 @@ func main() {
 		logger.Fatalw("Server failed to create CEL resolver", zap.Error(err))
 	}
 	svc := githubpipelineconfig.NewService(githubClient)
+	if cfg.CacheSize > 0 {
+		svc = vcspipelineconfig.NewCachedService(svc, vcspipelineconfig.CacheOptions{
+			Size: cfg.CacheSize,
+			TTL:  cfg.CacheTTL,
+		})
+	}
+	kubeClient, err := kubernetes.NewForConfig(kubeCfg)
+	if err != nil {
+		logger.Fatalw("Server failed to create Kubernetes client", zap.Error(err))
+	}
+	kubeSvc := kubepipelineconfig.NewService(kubeClient)
+	err = kubeSvc.Start(context.Background())
+	if err != nil {
+		logger.Fatalw("Server failed to start Kubernetes service", zap.Error(err))
+	}
+	includeNamespaces := cfg.IncludeNamespaces
+	if len(includeNamespaces) == 0 {
+		includeNamespaces = []string{clusterinterceptorupdater.GetNamespace()}
+	}
+	includes := pipelineconfig.NewIncludeResolver(pipelineconfig.DefaultIncludeDepth, map[string]pipelineconfig.IncludeLoader{
+		kubepipelineconfig.IncludeScheme:   kubepipelineconfig.NewIncludeLoader(kubeSvc, includeNamespaces),
+		githubpipelineconfig.IncludeScheme: vcspipelineconfig.NewIncludeLoader(svc, cfg.IncludeOwners),
+	})
 	startInformer()
 	intercepterName := getIntercepterName()
 	ns := clusterinterceptorupdater.GetNamespace()
 	certs := clusterinterceptorupdater.PrepareTLS(ctx, logger, kubeCfg, intercepterName, ns)
-	mux := newMux(svc, resolver, logger)
+	var reporter pipelineconfigreporter.Reporter
+	if cfg.ReportErrors {
+		reporter = pipelineconfigreporter.NewGithubReporter(githubClient)
+	}
+	mux := newMux(svc, resolver, includes, reporter, logger)
 	srv := &http.Server{
 		Addr:         cfg.Addr,
 		TLSConfig:    &tls.Config{Certificates: []tls.Certificate{certs}},
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ElementalCognition/tekton-toolbox/internal/chimiddleware"
+	"github.com/ElementalCognition/tekton-toolbox/internal/clusterinterceptorupdater"
+	"github.com/ElementalCognition/tekton-toolbox/internal/knativeinjection"
+	"github.com/ElementalCognition/tekton-toolbox/internal/serversignals"
+	"github.com/ElementalCognition/tekton-toolbox/internal/viperconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/gitlabpipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/kubepipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/triggers"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/signals"
+)
+
+type config struct {
+	Addr              string
+	GitlabURL         string        `mapstructure:"gitlab-url"`
+	GitlabToken       string        `mapstructure:"gitlab-token"`
+	CacheSize         int           `mapstructure:"cache-size"`
+	CacheTTL          time.Duration `mapstructure:"cache-ttl"`
+	IncludeNamespaces []string      `mapstructure:"include-namespaces"`
+	IncludeOwners     []string      `mapstructure:"include-owners"`
+}
+
+const (
+	component        = "gitlab-pipeline-config"
+	readTimeout      = 5 * time.Second
+	writeTimeout     = 20 * time.Second
+	idleTimeout      = 60 * time.Second
+	forceStopTimeout = 1 * time.Minute
+)
+
+func init() {
+	flag.String("config", "", "The path to the config file.")
+	flag.String("addr", "0.0.0.0:8443", "The address and port.")
+	flag.String("gitlab-url", gitlabpipelineconfig.DefaultBaseURL, "GitLab URL.")
+	flag.String("gitlab-token", "", "GitLab access token.")
+	flag.Int("cache-size", 1000, "The maximum number of cached configs, zero disables the cache.")
+	flag.Duration("cache-ttl", 0, "The time to keep a cached config, zero keeps it until evicted.")
+	flag.String("include-namespaces", "", "Comma-separated namespaces of ConfigMaps which configs may include, defaults to the interceptor namespace.")
+	flag.String("include-owners", "", "Comma-separated owners of repositories which configs may include.")
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+	pflag.Parse()
+}
+
+func newGitlabClient(cfg *config) *http.Client {
+	return &http.Client{Transport: gitlabpipelineconfig.NewTransport(cfg.GitlabToken)}
+}
+
+func newMux(
+	service vcspipelineconfig.Service,
+	resolver pipelineresolver.Resolver,
+	includes *pipelineconfig.IncludeResolver,
+	logger *zap.SugaredLogger,
+) *chi.Mux {
+	mux := chi.NewRouter()
+	mux.Group(func(r chi.Router) {
+		chimiddleware.WithHeartbeat(r)
+		chimiddleware.WithMetrics(r)
+	})
+	mux.Group(func(r chi.Router) {
+		r.Use(middleware.RequestID)
+		r.Use(chimiddleware.WithRequestID)
+		r.Use(chimiddleware.RequestLogger(logger))
+		r.Use(middleware.Recoverer)
+		r.Post("/", triggers.NewHandler(
+			vcspipelineconfig.NewInterceptor(
+				service,
+				resolver,
+				includes,
+				nil,
+			),
+		))
+	})
+	return mux
+}
+
+func getIntercepterName() string {
+	// Keep k8s service name and clusterintercepter name the same.
+	if ci, ok := os.LookupEnv("INTERCEPTER_NAME"); ok {
+		return ci
+	}
+	return "gitlab-pipeline-config"
+}
+
+func main() {
+	ctx := signals.NewContext()
+	kubeCfg := injection.ParseAndGetRESTConfigOrDie()
+	ctx, startInformer := injection.EnableInjectionOrDie(ctx, kubeCfg)
+	logger := knativeinjection.SetupLoggerOrDie(ctx, component)
+	ctx = logging.WithLogger(ctx, logger)
+	viperCfg, err := viperconfig.NewConfig(component, pflag.CommandLine)
+	if err != nil {
+		logger.Fatalw("Server failed to initialize config", zap.Error(err))
+	}
+	var cfg config
+	err = viperconfig.LoadConfig(viperCfg, &cfg)
+	if err != nil {
+		logger.Fatalw("Server failed to load config", zap.Error(err))
+	}
+	resolver, err := pipelineresolver.NewCelResolver()
+	if err != nil {
+		logger.Fatalw("Server failed to create CEL resolver", zap.Error(err))
+	}
+	svc := gitlabpipelineconfig.NewService(cfg.GitlabURL, newGitlabClient(&cfg))
+	if cfg.CacheSize > 0 {
+		svc = vcspipelineconfig.NewCachedService(svc, vcspipelineconfig.CacheOptions{
+			Size: cfg.CacheSize,
+			TTL:  cfg.CacheTTL,
+		})
+	}
+	kubeClient, err := kubernetes.NewForConfig(kubeCfg)
+	if err != nil {
+		logger.Fatalw("Server failed to create Kubernetes client", zap.Error(err))
+	}
+	kubeSvc := kubepipelineconfig.NewService(kubeClient)
+	err = kubeSvc.Start(context.Background())
+	if err != nil {
+		logger.Fatalw("Server failed to start Kubernetes service", zap.Error(err))
+	}
+	includeNamespaces := cfg.IncludeNamespaces
+	if len(includeNamespaces) == 0 {
+		includeNamespaces = []string{clusterinterceptorupdater.GetNamespace()}
+	}
+	includes := pipelineconfig.NewIncludeResolver(pipelineconfig.DefaultIncludeDepth, map[string]pipelineconfig.IncludeLoader{
+		kubepipelineconfig.IncludeScheme:   kubepipelineconfig.NewIncludeLoader(kubeSvc, includeNamespaces),
+		gitlabpipelineconfig.IncludeScheme: vcspipelineconfig.NewIncludeLoader(svc, cfg.IncludeOwners),
+	})
+	startInformer()
+	intercepterName := getIntercepterName()
+	ns := clusterinterceptorupdater.GetNamespace()
+	certs := clusterinterceptorupdater.PrepareTLS(ctx, logger, kubeCfg, intercepterName, ns)
+	mux := newMux(svc, resolver, includes, logger)
+	srv := &http.Server{
+		Addr:         cfg.Addr,
+		TLSConfig:    &tls.Config{Certificates: []tls.Certificate{certs}},
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+		IdleTimeout:  idleTimeout,
+		Handler:      mux,
+		BaseContext: func(_ net.Listener) context.Context {
+			return ctx
+		},
+	}
+	s := serversignals.Server{
+		Server:           srv,
+		Logger:           logger,
+		ForceStopTimeout: forceStopTimeout,
+	}
+	if err := s.StartAndWaitSignalsThenShutdown(context.Background()); err != http.ErrServerClosed {
+		logger.Fatalw("Server failed to shutdown", zap.Error(err))
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/ElementalCognition/tekton-toolbox/internal/serversignals"
 	"github.com/ElementalCognition/tekton-toolbox/internal/viperconfig"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/kubepipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/triggers"
 	"github.com/go-chi/chi"
@@ -29,7 +30,8 @@ import (
 )
 
 type config struct {
-	Addr string
+	Addr              string
+	IncludeNamespaces []string `mapstructure:"include-namespaces"`
 }
 
 const (
@@ -43,11 +45,13 @@ const (
 func newMux(
 	service kubepipelineconfig.Service,
 	resolver pipelineresolver.Resolver,
+	includes *pipelineconfig.IncludeResolver,
 	logger *zap.SugaredLogger,
 ) *chi.Mux {
 	mux := chi.NewRouter()
 	mux.Group(func(r chi.Router) {
 		chimiddleware.WithHeartbeat(r)
+		chimiddleware.WithMetrics(r)
 	})
 	mux.Group(func(r chi.Router) {
 		r.Use(middleware.RequestID)
@@ -58,6 +62,7 @@ func newMux(
 			kubepipelineconfig.NewInterceptor(
 				service,
 				resolver,
+				includes,
 			),
 		))
 	})
@@ -75,6 +80,7 @@ func getIntercepterName() string {
 func init() {
 	flag.String("config", "", "The path to the config file.")
 	flag.String("addr", "0.0.0.0:8443", "The address and port.")
+	flag.String("include-namespaces", "", "Comma-separated namespaces of ConfigMaps which configs may include, defaults to the interceptor namespace.")
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	pflag.Parse()
 }
@@ -103,6 +109,13 @@ func main() {
 		logger.Fatalw("Server failed to create Kubernetes client", zap.Error(err))
 	}
 	svc := kubepipelineconfig.NewService(kubeClient)
+	includeNamespaces := cfg.IncludeNamespaces
+	if len(includeNamespaces) == 0 {
+		includeNamespaces = []string{clusterinterceptorupdater.GetNamespace()}
+	}
+	includes := pipelineconfig.NewIncludeResolver(pipelineconfig.DefaultIncludeDepth, map[string]pipelineconfig.IncludeLoader{
+		kubepipelineconfig.IncludeScheme: kubepipelineconfig.NewIncludeLoader(svc, includeNamespaces),
+	})
 	err = svc.Start(context.Background())
 	if err != nil {
 		logger.Fatalw("Server failed to start Kubernetes service", zap.Error(err))
@@ -111,7 +124,7 @@ func main() {
 	intercepterName := getIntercepterName()
 	ns := clusterinterceptorupdater.GetNamespace()
 	certs := clusterinterceptorupdater.PrepareTLS(ctx, logger, kubeCfg, intercepterName, ns)
-	mux := newMux(svc, resolver, logger)
+	mux := newMux(svc, resolver, includes, logger)
 	srv := &http.Server{
 		Addr:         cfg.Addr,
 		TLSConfig:    &tls.Config{Certificates: []tls.Certificate{certs}},
@@ -15,24 +15,39 @@ import (
 	"github.com/ElementalCognition/tekton-toolbox/internal/knativeinjection"
 	"github.com/ElementalCognition/tekton-toolbox/internal/serversignals"
 	"github.com/ElementalCognition/tekton-toolbox/internal/viperconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/githubtransport"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfigreporter"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfigtrigger"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/triggers"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
+	"github.com/google/go-github/v43/github"
 	"github.com/spf13/pflag"
 	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	"go.uber.org/zap"
 	"gopkg.in/go-playground/pool.v3"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
 	"knative.dev/pkg/injection"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/signals"
 )
 
 type config struct {
-	Addr    string
-	Workers uint
+	Addr                 string
+	Workers              uint
+	GithubAppID          int64         `mapstructure:"github-app-id"`
+	GithubInstallationID int64         `mapstructure:"github-installation-id"`
+	GithubAppKey         string        `mapstructure:"github-app-key"`
+	ValuesNamespace      string        `mapstructure:"values-namespace"`
+	Secrets              []string      `mapstructure:"secrets"`
+	ConfigMaps           []string      `mapstructure:"config-maps"`
+	NeedsController      bool          `mapstructure:"needs-controller"`
+	QueueController      bool          `mapstructure:"queue-controller"`
+	DedupeWindow         time.Duration `mapstructure:"dedupe-window"`
 }
 
 const (
@@ -43,14 +58,52 @@ const (
 	forceStopTimeout = 1 * time.Second
 )
 
+// newReporter returns a reporter of config errors, if a GitHub App is configured.
+func newReporter(cfg *config) (pipelineconfigreporter.Reporter, error) {
+	if cfg.GithubAppID == 0 {
+		return nil, nil
+	}
+	githubTransport, err := githubtransport.NewTransport(cfg.GithubAppID, cfg.GithubInstallationID, cfg.GithubAppKey)
+	if err != nil {
+		return nil, err
+	}
+	githubClient := github.NewClient(&http.Client{Transport: githubTransport})
+	return pipelineconfigreporter.NewGithubReporter(githubClient), nil
+}
+
+// newResolver returns a CEL resolver, which reads allowed Secrets and ConfigMaps, if any.
+func newResolver(ctx context.Context, cfg *config, kubeCfg *rest.Config) (pipelineresolver.Resolver, error) {
+	if len(cfg.Secrets) == 0 && len(cfg.ConfigMaps) == 0 {
+		return pipelineresolver.NewCelResolver()
+	}
+	kubeClient, err := kubernetes.NewForConfig(kubeCfg)
+	if err != nil {
+		return nil, err
+	}
+	ns := cfg.ValuesNamespace
+	if ns == "" {
+		ns = clusterinterceptorupdater.GetNamespace()
+	}
+	lib, err := pipelineresolver.NewKubeLib(ctx, kubeClient, ns, pipelineresolver.KubeAllowlist{
+		Secrets:    cfg.Secrets,
+		ConfigMaps: cfg.ConfigMaps,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pipelineresolver.NewCachedCelResolver(pipelineresolver.DefaultProgramCacheSize, lib)
+}
+
 func newMux(
 	service pipelineconfigtrigger.Service,
 	resolver pipelineresolver.Resolver,
+	reporter pipelineconfigreporter.Reporter,
 	logger *zap.SugaredLogger,
 ) *chi.Mux {
 	mux := chi.NewRouter()
 	mux.Group(func(r chi.Router) {
 		chimiddleware.WithHeartbeat(r)
+		chimiddleware.WithMetrics(r)
 	})
 	mux.Group(func(r chi.Router) {
 		r.Use(middleware.RequestID)
@@ -61,6 +114,7 @@ func newMux(
 			pipelineconfigtrigger.NewInterceptor(
 				service,
 				resolver,
+				reporter,
 			),
 		))
 	})
@@ -79,6 +133,15 @@ func init() {
 	flag.String("config", "", "The path to the config file.")
 	flag.String("addr", "0.0.0.0:8443", "The address and port.")
 	flag.Int("workers", runtime.NumCPU(), "The number of workers to trigger pipelines.")
+	flag.Int64("github-app-id", 0, "GitHub App ID to report config errors as check runs.")
+	flag.Int64("github-installation-id", 0, "GitHub Installation ID.")
+	flag.String("github-app-key", "", "GitHub App key.")
+	flag.String("values-namespace", "", "The namespace of Secrets and ConfigMaps which expressions may read.")
+	flag.String("secrets", "", "Comma-separated names of Secrets which expressions may read.")
+	flag.String("config-maps", "", "Comma-separated names of ConfigMaps which expressions may read.")
+	flag.Bool("needs-controller", true, "Whether to start PipelineRuns which need other ones, once they succeed.")
+	flag.Bool("queue-controller", true, "Whether to start pending PipelineRuns of concurrency groups, as slots free up.")
+	flag.Duration("dedupe-window", time.Hour, "The time to skip PipelineRuns of a redelivered event, zero disables it.")
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	pflag.Parse()
 }
@@ -102,17 +165,50 @@ func main() {
 	if err != nil {
 		logger.Fatalw("Server failed to create Tekton client", zap.Error(err))
 	}
-	resolver, err := pipelineresolver.NewCelResolver()
+	resolver, err := newResolver(ctx, &cfg, kubeCfg)
 	if err != nil {
 		logger.Fatalw("Server failed to create CEL resolver", zap.Error(err))
 	}
+	reporter, err := newReporter(&cfg)
+	if err != nil {
+		logger.Fatalw("Server failed to create GitHub client", zap.Error(err))
+	}
 	startInformer()
 	intercepterName := getIntercepterName()
 	ns := clusterinterceptorupdater.GetNamespace()
 	certs := clusterinterceptorupdater.PrepareTLS(ctx, logger, kubeCfg, intercepterName, ns)
 	p := pool.NewLimited(cfg.Workers)
-	svc := pipelineconfigtrigger.NewService(tektonClient, p)
-	mux := newMux(svc, resolver, logger)
+	svc := pipelineconfigtrigger.NewService(tektonClient, p, cfg.DedupeWindow)
+	var controllers []pipelineconfigtrigger.Controller
+	if cfg.NeedsController {
+		controllers = append(controllers, pipelineconfigtrigger.NewNeedsController(tektonClient))
+	}
+	if cfg.QueueController {
+		kubeClient, err := kubernetes.NewForConfig(kubeCfg)
+		if err != nil {
+			logger.Fatalw("Server failed to create Kubernetes client", zap.Error(err))
+		}
+		hostname, err := os.Hostname()
+		if err != nil {
+			logger.Fatalw("Server failed to get hostname", zap.Error(err))
+		}
+		// Replicas start pending PipelineRuns one at a time, since the queue controller runs in the leader only.
+		controllers = append(controllers, pipelineconfigtrigger.NewLeaderElectedController(
+			pipelineconfigtrigger.NewQueueController(tektonClient),
+			kubeClient,
+			ns,
+			component+"-queue-controller",
+			hostname+"_"+string(uuid.NewUUID()),
+		))
+	}
+	for _, c := range controllers {
+		go func(c pipelineconfigtrigger.Controller) {
+			if err := c.Run(ctx); err != nil {
+				logger.Fatalw("Server failed to run controller", zap.Error(err))
+			}
+		}(c)
+	}
+	mux := newMux(svc, resolver, reporter, logger)
 	srv := &http.Server{
 		Addr:         cfg.Addr,
 		TLSConfig:    &tls.Config{Certificates: []tls.Certificate{certs}},
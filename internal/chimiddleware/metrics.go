@@ -0,0 +1,12 @@
+package chimiddleware
+
+import (
+	"github.com/go-chi/chi"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsPattern = "/metrics"
+
+func WithMetrics(r chi.Router) {
+	r.Handle(metricsPattern, promhttp.Handler())
+}
@@ -0,0 +1,174 @@
+package bitbucketpipelineconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/tokentransport"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	tokenHeader   = "Authorization"
+	filesPageSize = 1000
+)
+
+type filesPage struct {
+	Values        []string `json:"values"`
+	IsLastPage    bool     `json:"isLastPage"`
+	NextPageStart int      `json:"nextPageStart"`
+}
+
+// Bitbucket Server identifies repositories by project key and repository slug,
+// see https://confluence.atlassian.com/bitbucketserver/event-payload-938025882.html.
+var defaultParams = map[string]string{
+	"owner": `"pullRequest" in body ? body.pullRequest.toRef.repository.project.key : body.repository.project.key`,
+	"repo":  `"pullRequest" in body ? body.pullRequest.toRef.repository.slug : body.repository.slug`,
+	"ref":   `"pullRequest" in body ? body.pullRequest.fromRef.latestCommit : body.changes[0].toHash`,
+}
+
+type service struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+var _ vcspipelineconfig.Service = (*service)(nil)
+var _ vcspipelineconfig.ParamDefaulter = (*service)(nil)
+
+func (s *service) repoURL(owner, repo string) string {
+	return fmt.Sprintf(
+		"%s/rest/api/1.0/projects/%s/repos/%s",
+		s.baseURL,
+		url.PathEscape(owner),
+		url.PathEscape(repo),
+	)
+}
+
+// fileURL builds a raw file URL, see https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-raw-path-get.
+func (s *service) fileURL(owner, repo, ref, path string) string {
+	return fmt.Sprintf("%s/raw/%s?at=%s", s.repoURL(owner, repo), vcspipelineconfig.EscapePath(path), url.QueryEscape(ref))
+}
+
+// filesURL builds a files listing URL of a page, see https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-files-path-get.
+func (s *service) filesURL(owner, repo, ref, path string, start int) string {
+	return fmt.Sprintf(
+		"%s/files/%s?at=%s&limit=%d&start=%d",
+		s.repoURL(owner, repo),
+		vcspipelineconfig.EscapePath(path),
+		url.QueryEscape(ref),
+		filesPageSize,
+		start,
+	)
+}
+
+// commitURL builds a commit URL, which resolves a ref, see https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-commits-commitid-get.
+func (s *service) commitURL(owner, repo, ref string) string {
+	return fmt.Sprintf("%s/commits/%s", s.repoURL(owner, repo), url.PathEscape(ref))
+}
+
+func (s *service) get(ctx context.Context, u string) ([]byte, *http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, res, fmt.Errorf("unable to get %s: %s", req.URL.Path, res.Status)
+	}
+	buf, err := io.ReadAll(res.Body)
+	return buf, res, err
+}
+
+// getDir lists every page of files, which Bitbucket links by `nextPageStart` until `isLastPage`.
+func (s *service) getDir(ctx context.Context, owner, repo, ref, path string) ([]string, *http.Response, error) {
+	dir := []string{}
+	start := 0
+	for {
+		buf, res, err := s.get(ctx, s.filesURL(owner, repo, ref, path, start))
+		if err != nil {
+			return nil, res, err
+		}
+		var page filesPage
+		err = json.Unmarshal(buf, &page)
+		if err != nil {
+			return nil, res, err
+		}
+		// Files are listed recursively, relative to path.
+		for _, f := range page.Values {
+			if !strings.Contains(f, "/") {
+				dir = append(dir, f)
+			}
+		}
+		if page.IsLastPage || page.NextPageStart <= start {
+			return dir, res, nil
+		}
+		start = page.NextPageStart
+	}
+}
+
+func (s *service) contentFunc(owner, repo, ref string) vcspipelineconfig.ContentFunc {
+	return func(ctx context.Context, path string) ([]byte, []string, error) {
+		logger := logging.FromContext(ctx)
+		content, res, err := s.get(ctx, s.fileURL(owner, repo, ref, path))
+		var dir []string
+		if err != nil && res != nil && res.StatusCode == http.StatusNotFound {
+			dir, res, err = s.getDir(ctx, owner, repo, ref, path)
+		}
+		if err != nil && res != nil && res.StatusCode == http.StatusNotFound {
+			err = vcspipelineconfig.NotFound(err, func() error {
+				_, _, err := s.get(ctx, s.commitURL(owner, repo, ref))
+				return err
+			})
+		}
+		if err != nil {
+			keyAndVals := []any{zap.String("path", path), zap.Error(err)}
+			if res != nil {
+				keyAndVals = append(keyAndVals, zap.String("responseStatus", res.Status))
+			}
+			logger.Errorw("Service failed to fetch config", keyAndVals...)
+			return nil, nil, err
+		}
+		logger.Debugf("config file content %s: %s", path, content)
+		return content, dir, nil
+	}
+}
+
+func (s *service) DefaultParams() map[string]string {
+	return defaultParams
+}
+
+func (s *service) Get(ctx context.Context, owner, repo, ref, path string) (*pipelineconfig.Config, error) {
+	logger := logging.FromContext(ctx)
+	logger = logger.With(
+		zap.String("owner", owner),
+		zap.String("repository", repo),
+	)
+	logger.Infow("Service started fetch config")
+	return vcspipelineconfig.ReadConfig(logging.WithLogger(ctx, logger), path, s.contentFunc(owner, repo, ref))
+}
+
+func NewTransport(token string) http.RoundTripper {
+	return tokentransport.NewTransport(tokenHeader, fmt.Sprintf("Bearer %s", token))
+}
+
+func NewService(
+	baseURL string,
+	httpClient *http.Client,
+) vcspipelineconfig.Service {
+	return &service{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
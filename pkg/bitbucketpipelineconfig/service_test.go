@@ -0,0 +1,74 @@
+package bitbucketpipelineconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig/vcspipelineconfigtest"
+)
+
+func newTestService(t *testing.T, repository *vcspipelineconfigtest.Repository, token string) vcspipelineconfig.Service {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/1.0/projects/{owner}/repos/{repo}/raw/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(tokenHeader) != fmt.Sprintf("Bearer %s", repository.Token) {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		content, ok := repository.Lookup(r.PathValue("owner"), r.PathValue("repo"), r.URL.Query().Get("at"), r.PathValue("path"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	})
+	mux.HandleFunc("GET /rest/api/1.0/projects/{owner}/repos/{repo}/files/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(tokenHeader) != fmt.Sprintf("Bearer %s", repository.Token) {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		owner, repo, ref, path := r.PathValue("owner"), r.PathValue("repo"), r.URL.Query().Get("at"), r.PathValue("path")
+		if !repository.HasDir(path) || repository.Ref != ref || repository.Owner != owner || repository.Repo != repo {
+			http.NotFound(w, r)
+			return
+		}
+		var files []string
+		for p := range repository.Files {
+			if rel, ok := strings.CutPrefix(p, path+"/"); ok {
+				files = append(files, rel)
+			}
+		}
+		// Every page has a single file, so listings of a few files are paginated.
+		slices.Sort(files)
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		page := filesPage{IsLastPage: start+1 >= len(files), NextPageStart: start + 1}
+		if start < len(files) {
+			page.Values = files[start : start+1]
+		}
+		_ = json.NewEncoder(w).Encode(&page)
+	})
+	mux.HandleFunc("GET /rest/api/1.0/projects/{owner}/repos/{repo}/commits/{ref}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(tokenHeader) != fmt.Sprintf("Bearer %s", repository.Token) {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		if !repository.HasRef(r.PathValue("owner"), r.PathValue("repo"), r.PathValue("ref")) {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("{}"))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return NewService(srv.URL, &http.Client{Transport: NewTransport(token)})
+}
+
+func TestService(t *testing.T) {
+	vcspipelineconfigtest.Run(t, newTestService)
+}
@@ -0,0 +1,168 @@
+package giteapipelineconfig
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/tokentransport"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+const tokenHeader = "Authorization"
+
+type contentsEntry struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Content string `json:"content"`
+}
+
+// Gitea webhook payloads follow GitHub conventions, see https://docs.gitea.com/usage/webhooks.
+var defaultParams = map[string]string{
+	"owner": "body.repository.owner.login",
+	"repo":  "body.repository.name",
+	"ref":   `"pull_request" in body ? body.pull_request.head.sha : body.after`,
+}
+
+type service struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+var _ vcspipelineconfig.Service = (*service)(nil)
+var _ vcspipelineconfig.ParamDefaulter = (*service)(nil)
+
+// contentsURL builds a contents API URL, see https://gitea.com/api/swagger#/repository/repoGetContents.
+func (s *service) contentsURL(owner, repo, ref, path string) string {
+	return fmt.Sprintf(
+		"%s/api/v1/repos/%s/%s/contents/%s?ref=%s",
+		s.baseURL,
+		url.PathEscape(owner),
+		url.PathEscape(repo),
+		vcspipelineconfig.EscapePath(path),
+		url.QueryEscape(ref),
+	)
+}
+
+// commitURL builds a commit API URL, which resolves a ref, see https://gitea.com/api/swagger#/repository/repoGetSingleCommit.
+func (s *service) commitURL(owner, repo, ref string) string {
+	return fmt.Sprintf(
+		"%s/api/v1/repos/%s/%s/git/commits/%s",
+		s.baseURL,
+		url.PathEscape(owner),
+		url.PathEscape(repo),
+		url.PathEscape(ref),
+	)
+}
+
+func (s *service) resolve(ctx context.Context, owner, repo, ref string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.commitURL(owner, repo, ref), nil)
+	if err != nil {
+		return err
+	}
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to get %s of %s/%s: %s", ref, owner, repo, res.Status)
+	}
+	return nil
+}
+
+func (s *service) getContents(ctx context.Context, owner, repo, ref, path string) ([]byte, []string, *http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.contentsURL(owner, repo, ref, path), nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, nil, res, fmt.Errorf("unable to get %s from %s/%s at %s: %s", path, owner, repo, ref, res.Status)
+	}
+	buf, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, res, err
+	}
+	if len(buf) > 0 && buf[0] == '[' {
+		var entries []contentsEntry
+		if err := json.Unmarshal(buf, &entries); err != nil {
+			return nil, nil, res, err
+		}
+		dir := []string{}
+		for _, e := range entries {
+			if e.Type == "file" {
+				dir = append(dir, e.Name)
+			}
+		}
+		return nil, dir, res, nil
+	}
+	var e contentsEntry
+	if err := json.Unmarshal(buf, &e); err != nil {
+		return nil, nil, res, err
+	}
+	content, err := base64.StdEncoding.DecodeString(e.Content)
+	return content, nil, res, err
+}
+
+func (s *service) contentFunc(owner, repo, ref string) vcspipelineconfig.ContentFunc {
+	return func(ctx context.Context, path string) ([]byte, []string, error) {
+		logger := logging.FromContext(ctx)
+		content, dir, res, err := s.getContents(ctx, owner, repo, ref, path)
+		if err != nil && res != nil && res.StatusCode == http.StatusNotFound {
+			err = vcspipelineconfig.NotFound(err, func() error {
+				return s.resolve(ctx, owner, repo, ref)
+			})
+		}
+		if err != nil {
+			keyAndVals := []any{zap.String("path", path), zap.Error(err)}
+			if res != nil {
+				keyAndVals = append(keyAndVals, zap.String("responseStatus", res.Status))
+			}
+			logger.Errorw("Service failed to fetch config", keyAndVals...)
+			return nil, nil, err
+		}
+		logger.Debugf("config file content %s: %s", path, content)
+		return content, dir, nil
+	}
+}
+
+func (s *service) DefaultParams() map[string]string {
+	return defaultParams
+}
+
+func (s *service) Get(ctx context.Context, owner, repo, ref, path string) (*pipelineconfig.Config, error) {
+	logger := logging.FromContext(ctx)
+	logger = logger.With(
+		zap.String("owner", owner),
+		zap.String("repository", repo),
+	)
+	logger.Infow("Service started fetch config")
+	return vcspipelineconfig.ReadConfig(logging.WithLogger(ctx, logger), path, s.contentFunc(owner, repo, ref))
+}
+
+func NewTransport(token string) http.RoundTripper {
+	return tokentransport.NewTransport(tokenHeader, fmt.Sprintf("token %s", token))
+}
+
+func NewService(
+	baseURL string,
+	httpClient *http.Client,
+) vcspipelineconfig.Service {
+	return &service{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
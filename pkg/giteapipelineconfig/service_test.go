@@ -0,0 +1,59 @@
+package giteapipelineconfig
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig/vcspipelineconfigtest"
+)
+
+func newTestService(t *testing.T, repository *vcspipelineconfigtest.Repository, token string) vcspipelineconfig.Service {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(tokenHeader) != fmt.Sprintf("token %s", repository.Token) {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		owner, repo, ref, path := r.PathValue("owner"), r.PathValue("repo"), r.URL.Query().Get("ref"), r.PathValue("path")
+		if content, ok := repository.Lookup(owner, repo, ref, path); ok {
+			_ = json.NewEncoder(w).Encode(&contentsEntry{
+				Name:    path,
+				Type:    "file",
+				Content: base64.StdEncoding.EncodeToString([]byte(content)),
+			})
+			return
+		}
+		if names, ok := repository.List(owner, repo, ref, path); ok {
+			var dir []contentsEntry
+			for _, name := range names {
+				dir = append(dir, contentsEntry{Name: name, Type: "file"})
+			}
+			_ = json.NewEncoder(w).Encode(dir)
+			return
+		}
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/git/commits/{ref}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(tokenHeader) != fmt.Sprintf("token %s", repository.Token) {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		if !repository.HasRef(r.PathValue("owner"), r.PathValue("repo"), r.PathValue("ref")) {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("{}"))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return NewService(srv.URL, &http.Client{Transport: NewTransport(token)})
+}
+
+func TestService(t *testing.T) {
+	vcspipelineconfigtest.Run(t, newTestService)
+}
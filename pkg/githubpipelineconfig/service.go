@@ -2,6 +2,7 @@ package githubpipelineconfig
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig"
@@ -10,39 +11,139 @@ import (
 	"knative.dev/pkg/logging"
 )
 
-const configFile = ".tekton.yaml"
+const (
+	// IncludeScheme is a scheme of includes like `github://owner/repo@ref/path`.
+	IncludeScheme = "github"
+	nullSHA       = "0000000000000000000000000000000000000000"
+	// maxCompareFiles is the maximum number of files the compare API lists, so a comparison of as many files may
+	// miss some of them.
+	maxCompareFiles = 300
+	// maxPullRequestFiles is the maximum number of files the pull request files API lists.
+	maxPullRequestFiles = 3000
+)
 
 type service struct {
 	githubClient *github.Client
 }
 
 var _ vcspipelineconfig.Service = (*service)(nil)
+var _ vcspipelineconfig.ChangedFilesLister = (*service)(nil)
+
+func (s *service) contentFunc(owner, repo, ref string) vcspipelineconfig.ContentFunc {
+	return func(ctx context.Context, path string) ([]byte, []string, error) {
+		logger := logging.FromContext(ctx)
+		opts := &github.RepositoryContentGetOptions{Ref: ref}
+		c, d, res, err := s.githubClient.Repositories.GetContents(ctx, owner, repo, path, opts)
+		if err != nil && res != nil && res.StatusCode == http.StatusNotFound {
+			err = vcspipelineconfig.NotFound(err, func() error {
+				_, _, err := s.githubClient.Repositories.GetCommitSHA1(ctx, owner, repo, ref, "")
+				return err
+			})
+		}
+		if err != nil {
+			keyAndVals := []any{zap.String("path", path), zap.Error(err)}
+			if res != nil {
+				keyAndVals = append(keyAndVals, zap.String("responseStatus", res.Status))
+			}
+			logger.Errorw("Service failed to fetch config", keyAndVals...)
+			return nil, nil, err
+		}
+		if c == nil {
+			dir := []string{}
+			for _, e := range d {
+				if e.GetType() == "file" {
+					dir = append(dir, e.GetName())
+				}
+			}
+			return nil, dir, nil
+		}
+		content, err := c.GetContent()
+		if err != nil {
+			return nil, nil, err
+		}
+		logger.Debugf("config file content %s: %s", path, content)
+		return []byte(content), nil, nil
+	}
+}
 
-func (s *service) Get(ctx context.Context, owner, repo, ref string) (*pipelineconfig.Config, error) {
+func (s *service) Get(ctx context.Context, owner, repo, ref, path string) (*pipelineconfig.Config, error) {
 	logger := logging.FromContext(ctx)
 	logger = logger.With(
 		zap.String("owner", owner),
 		zap.String("repository", repo),
 	)
 	logger.Infow("Service started fetch config")
-	opts := &github.RepositoryContentGetOptions{Ref: ref}
-	c, _, res, err := s.githubClient.Repositories.GetContents(ctx, owner, repo, configFile, opts)
-	if err != nil {
-		logger.Errorw("Service failed to fetch config",
-			zap.String("responseStatus", res.Status),
-			zap.Error(err),
-		)
-		return nil, err
+	return vcspipelineconfig.ReadConfig(logging.WithLogger(ctx, logger), path, s.contentFunc(owner, repo, ref))
+}
+
+func appendFiles(files []string, cf []*github.CommitFile) []string {
+	for _, f := range cf {
+		files = append(files, f.GetFilename())
+		if f.GetPreviousFilename() != "" {
+			files = append(files, f.GetPreviousFilename())
+		}
+	}
+	return files
+}
+
+func (s *service) pullRequestFiles(ctx context.Context, owner, repo string, number int) ([]string, error) {
+	files := []string{}
+	n := 0
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		cf, res, err := s.githubClient.PullRequests.ListFiles(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, err
+		}
+		files = appendFiles(files, cf)
+		n += len(cf)
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+	if n >= maxPullRequestFiles {
+		logging.FromContext(ctx).Warnw("Service skipped truncated pull request files", zap.Int("files", n))
+		return nil, nil
 	}
-	content, err := c.GetContent()
+	return files, nil
+}
+
+func (s *service) compareFiles(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+	// Files are listed on the first page only, up to maxCompareFiles, so a single commit per page is enough.
+	c, _, err := s.githubClient.Repositories.CompareCommits(ctx, owner, repo, base, head, &github.ListOptions{PerPage: 1})
 	if err != nil {
 		return nil, err
 	}
-	logger.Debugf("config file content %s: %s", configFile, content)
+	if len(c.Files) >= maxCompareFiles {
+		// Changed files are unknown, rather than a part of them, so path filters are not applied.
+		logging.FromContext(ctx).Warnw("Service skipped truncated compare files", zap.Int("files", len(c.Files)))
+		return nil, nil
+	}
+	return appendFiles([]string{}, c.Files), nil
+}
 
-	cfg := &pipelineconfig.Config{}
-	err = cfg.UnmarshalYAML([]byte(content))
-	return cfg, err
+func (s *service) ChangedFiles(ctx context.Context, owner, repo string, body map[string]interface{}) ([]string, error) {
+	logger := logging.FromContext(ctx)
+	logger = logger.With(
+		zap.String("owner", owner),
+		zap.String("repository", repo),
+	)
+	if pr, ok := body["pull_request"].(map[string]interface{}); ok {
+		number, ok := pr["number"].(float64)
+		if !ok {
+			return nil, nil
+		}
+		logger.Infow("Service started list pull request files", zap.Int("number", int(number)))
+		return s.pullRequestFiles(ctx, owner, repo, int(number))
+	}
+	before, _ := body["before"].(string)
+	after, _ := body["after"].(string)
+	if before == "" || after == "" || before == nullSHA || after == nullSHA {
+		return nil, nil
+	}
+	logger.Infow("Service started compare commits", zap.String("base", before), zap.String("head", after))
+	return s.compareFiles(ctx, owner, repo, before, after)
 }
 
 func NewService(
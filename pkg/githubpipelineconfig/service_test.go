@@ -0,0 +1,139 @@
+package githubpipelineconfig
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/tokentransport"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig/vcspipelineconfigtest"
+	"github.com/google/go-github/v43/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestService(t *testing.T, repository *vcspipelineconfigtest.Repository, token string) vcspipelineconfig.Service {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/{owner}/{repo}/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != fmt.Sprintf("token %s", repository.Token) {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		owner, repo, ref, path := r.PathValue("owner"), r.PathValue("repo"), r.URL.Query().Get("ref"), r.PathValue("path")
+		if content, ok := repository.Lookup(owner, repo, ref, path); ok {
+			_ = json.NewEncoder(w).Encode(&github.RepositoryContent{
+				Type:     github.String("file"),
+				Encoding: github.String("base64"),
+				Content:  github.String(base64.StdEncoding.EncodeToString([]byte(content))),
+			})
+			return
+		}
+		if names, ok := repository.List(owner, repo, ref, path); ok {
+			var dir []*github.RepositoryContent
+			for _, name := range names {
+				dir = append(dir, &github.RepositoryContent{
+					Type: github.String("file"),
+					Name: github.String(name),
+				})
+			}
+			_ = json.NewEncoder(w).Encode(dir)
+			return
+		}
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("GET /repos/{owner}/{repo}/commits/{ref}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != fmt.Sprintf("token %s", repository.Token) {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		if !repository.HasRef(r.PathValue("owner"), r.PathValue("repo"), r.PathValue("ref")) {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(repository.Ref))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	githubClient := github.NewClient(&http.Client{
+		Transport: tokentransport.NewTransport("Authorization", fmt.Sprintf("token %s", token)),
+	})
+	baseURL, err := url.Parse(srv.URL + "/")
+	assert.Nil(t, err)
+	githubClient.BaseURL = baseURL
+	return NewService(githubClient)
+}
+
+func TestService(t *testing.T) {
+	vcspipelineconfigtest.Run(t, newTestService)
+}
+
+func TestService_ChangedFiles(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/foo/bar/compare/{basehead}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "a...b", r.PathValue("basehead"))
+		_ = json.NewEncoder(w).Encode(&github.CommitsComparison{
+			Files: []*github.CommitFile{
+				{Filename: github.String("services/foo/main.go")},
+				{Filename: github.String("services/baz/go.mod"), PreviousFilename: github.String("services/bar/go.mod")},
+			},
+		})
+	})
+	mux.HandleFunc("GET /repos/foo/bar/pulls/1/files", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]*github.CommitFile{
+			{Filename: github.String("README.md")},
+		})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	githubClient := github.NewClient(nil)
+	baseURL, err := url.Parse(srv.URL + "/")
+	assert.Nil(t, err)
+	githubClient.BaseURL = baseURL
+	svc := NewService(githubClient).(vcspipelineconfig.ChangedFilesLister)
+	files, err := svc.ChangedFiles(context.TODO(), "foo", "bar", map[string]interface{}{
+		"before": "a",
+		"after":  "b",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"services/foo/main.go", "services/baz/go.mod", "services/bar/go.mod"}, files)
+	files, err = svc.ChangedFiles(context.TODO(), "foo", "bar", map[string]interface{}{
+		"pull_request": map[string]interface{}{"number": float64(1)},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"README.md"}, files)
+	files, err = svc.ChangedFiles(context.TODO(), "foo", "bar", map[string]interface{}{
+		"before": nullSHA,
+		"after":  "b",
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, files)
+}
+
+func TestService_ChangedFiles_Truncated(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/foo/bar/compare/{basehead}", func(w http.ResponseWriter, r *http.Request) {
+		files := make([]*github.CommitFile, maxCompareFiles)
+		for i := range files {
+			files[i] = &github.CommitFile{Filename: github.String(fmt.Sprintf("file%d", i))}
+		}
+		_ = json.NewEncoder(w).Encode(&github.CommitsComparison{Files: files})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	githubClient := github.NewClient(nil)
+	baseURL, err := url.Parse(srv.URL + "/")
+	assert.Nil(t, err)
+	githubClient.BaseURL = baseURL
+	svc := NewService(githubClient).(vcspipelineconfig.ChangedFilesLister)
+	files, err := svc.ChangedFiles(context.TODO(), "foo", "bar", map[string]interface{}{
+		"before": "a",
+		"after":  "b",
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, files)
+}
@@ -0,0 +1,168 @@
+package gitlabpipelineconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/tokentransport"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	DefaultBaseURL = "https://gitlab.com"
+	// IncludeScheme is a scheme of includes like `gitlab://owner/repo@ref/path`.
+	IncludeScheme = "gitlab"
+	tokenHeader   = "PRIVATE-TOKEN"
+	treePageSize  = 100
+)
+
+var errEmptyTree = errors.New("empty tree")
+
+type treeEntry struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type service struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+var _ vcspipelineconfig.Service = (*service)(nil)
+
+func (s *service) projectURL(owner, repo string) string {
+	return fmt.Sprintf("%s/api/v4/projects/%s", s.baseURL, url.PathEscape(fmt.Sprintf("%s/%s", owner, repo)))
+}
+
+// fileURL builds a repository files API URL, see https://docs.gitlab.com/ee/api/repository_files.html.
+func (s *service) fileURL(owner, repo, ref, path string) string {
+	return fmt.Sprintf(
+		"%s/repository/files/%s/raw?ref=%s",
+		s.projectURL(owner, repo),
+		url.PathEscape(path),
+		url.QueryEscape(ref),
+	)
+}
+
+// treeURL builds a repository tree API URL of a page, see https://docs.gitlab.com/ee/api/repositories.html#list-repository-tree.
+func (s *service) treeURL(owner, repo, ref, path, page string) string {
+	return fmt.Sprintf(
+		"%s/repository/tree?path=%s&ref=%s&per_page=%d&page=%s",
+		s.projectURL(owner, repo),
+		url.QueryEscape(path),
+		url.QueryEscape(ref),
+		treePageSize,
+		url.QueryEscape(page),
+	)
+}
+
+// commitURL builds a commit API URL, which resolves a ref, see https://docs.gitlab.com/ee/api/commits.html#get-a-single-commit.
+func (s *service) commitURL(owner, repo, ref string) string {
+	return fmt.Sprintf("%s/repository/commits/%s", s.projectURL(owner, repo), url.PathEscape(ref))
+}
+
+func (s *service) get(ctx context.Context, u string) ([]byte, *http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, res, fmt.Errorf("unable to get %s: %s", req.URL.Path, res.Status)
+	}
+	buf, err := io.ReadAll(res.Body)
+	return buf, res, err
+}
+
+// getDir lists every page of a tree, which GitLab links by the `X-Next-Page` header.
+func (s *service) getDir(ctx context.Context, owner, repo, ref, path string) ([]string, *http.Response, error) {
+	dir := []string{}
+	var res *http.Response
+	for page := "1"; page != ""; page = res.Header.Get("X-Next-Page") {
+		var buf []byte
+		var err error
+		buf, res, err = s.get(ctx, s.treeURL(owner, repo, ref, path, page))
+		if err != nil {
+			return nil, res, err
+		}
+		var entries []treeEntry
+		err = json.Unmarshal(buf, &entries)
+		if err != nil {
+			return nil, res, err
+		}
+		// GitLab responds with an empty tree for paths which do not exist at ref.
+		if len(entries) == 0 && page == "1" {
+			return nil, res, fmt.Errorf("%w: unable to get %s from %s/%s at %s", errEmptyTree, path, owner, repo, ref)
+		}
+		for _, e := range entries {
+			if e.Type == "blob" {
+				dir = append(dir, e.Name)
+			}
+		}
+	}
+	return dir, res, nil
+}
+
+func (s *service) contentFunc(owner, repo, ref string) vcspipelineconfig.ContentFunc {
+	return func(ctx context.Context, path string) ([]byte, []string, error) {
+		logger := logging.FromContext(ctx)
+		content, res, err := s.get(ctx, s.fileURL(owner, repo, ref, path))
+		var dir []string
+		if err != nil && res != nil && res.StatusCode == http.StatusNotFound {
+			dir, res, err = s.getDir(ctx, owner, repo, ref, path)
+		}
+		if err != nil && (errors.Is(err, errEmptyTree) || res != nil && res.StatusCode == http.StatusNotFound) {
+			err = vcspipelineconfig.NotFound(err, func() error {
+				_, _, err := s.get(ctx, s.commitURL(owner, repo, ref))
+				return err
+			})
+		}
+		if err != nil {
+			keyAndVals := []any{zap.String("path", path), zap.Error(err)}
+			if res != nil {
+				keyAndVals = append(keyAndVals, zap.String("responseStatus", res.Status))
+			}
+			logger.Errorw("Service failed to fetch config", keyAndVals...)
+			return nil, nil, err
+		}
+		logger.Debugf("config file content %s: %s", path, content)
+		return content, dir, nil
+	}
+}
+
+func (s *service) Get(ctx context.Context, owner, repo, ref, path string) (*pipelineconfig.Config, error) {
+	logger := logging.FromContext(ctx)
+	logger = logger.With(
+		zap.String("owner", owner),
+		zap.String("repository", repo),
+	)
+	logger.Infow("Service started fetch config")
+	return vcspipelineconfig.ReadConfig(logging.WithLogger(ctx, logger), path, s.contentFunc(owner, repo, ref))
+}
+
+func NewTransport(token string) http.RoundTripper {
+	return tokentransport.NewTransport(tokenHeader, token)
+}
+
+func NewService(
+	baseURL string,
+	httpClient *http.Client,
+) vcspipelineconfig.Service {
+	return &service{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
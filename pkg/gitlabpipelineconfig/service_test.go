@@ -0,0 +1,67 @@
+package gitlabpipelineconfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig/vcspipelineconfigtest"
+)
+
+func newTestService(t *testing.T, repository *vcspipelineconfigtest.Repository, token string) vcspipelineconfig.Service {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/projects/{project}/repository/files/{path}/raw", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(tokenHeader) != repository.Token {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		owner, repo, _ := strings.Cut(r.PathValue("project"), "/")
+		content, ok := repository.Lookup(owner, repo, r.URL.Query().Get("ref"), r.PathValue("path"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	})
+	mux.HandleFunc("GET /api/v4/projects/{project}/repository/tree", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(tokenHeader) != repository.Token {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		owner, repo, _ := strings.Cut(r.PathValue("project"), "/")
+		names, _ := repository.List(owner, repo, r.URL.Query().Get("ref"), r.URL.Query().Get("path"))
+		// Every page has a single entry, so trees of a few files are paginated.
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		entries := []treeEntry{}
+		if page >= 1 && page <= len(names) {
+			entries = append(entries, treeEntry{Name: names[page-1], Type: "blob"})
+		}
+		if page < len(names) {
+			w.Header().Set("X-Next-Page", strconv.Itoa(page+1))
+		}
+		_ = json.NewEncoder(w).Encode(entries)
+	})
+	mux.HandleFunc("GET /api/v4/projects/{project}/repository/commits/{ref}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(tokenHeader) != repository.Token {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		owner, repo, _ := strings.Cut(r.PathValue("project"), "/")
+		if !repository.HasRef(owner, repo, r.PathValue("ref")) {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("{}"))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return NewService(srv.URL, &http.Client{Transport: NewTransport(token)})
+}
+
+func TestService(t *testing.T) {
+	vcspipelineconfigtest.Run(t, newTestService)
+}
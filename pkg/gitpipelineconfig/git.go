@@ -0,0 +1,49 @@
+package gitpipelineconfig
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+type git struct {
+	dir    string
+	config []string
+}
+
+func (g *git) run(ctx context.Context, args ...string) ([]byte, error) {
+	var cmdArgs []string
+	for _, c := range g.config {
+		cmdArgs = append(cmdArgs, "-c", c)
+	}
+	if len(g.dir) > 0 {
+		cmdArgs = append(cmdArgs, "-C", g.dir)
+	}
+	cmdArgs = append(cmdArgs, args...)
+	cmd := exec.CommandContext(ctx, "git", cmdArgs...)
+	// Messages are not translated, so missingPath can match them.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "LC_ALL=C")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// exists reports whether object exists, eg. `<commit>^{tree}`.
+func (g *git) exists(ctx context.Context, object string) bool {
+	_, err := g.run(ctx, "cat-file", "-e", object)
+	return err == nil
+}
+
+// missingPath reports whether err of `git cat-file` is caused by a path which does not exist in a commit, unlike eg.
+// a failure to fetch a missing object of a partial clone from the remote. Git reports a missing commit the same way,
+// so the commit has to be checked separately.
+func missingPath(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "does not exist in")
+}
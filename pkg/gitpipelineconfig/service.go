@@ -0,0 +1,215 @@
+package gitpipelineconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// IncludeScheme is a scheme of includes like `git://owner/repo@ref/path`.
+	IncludeScheme = "git"
+	remoteName    = "origin"
+)
+
+type service struct {
+	remoteURL  *template.Template
+	cacheDir   string
+	authHeader string
+	locks      sync.Map
+}
+
+var _ vcspipelineconfig.Service = (*service)(nil)
+
+// pathSegment escapes a name of an owner or a repository, so it is a single segment of a remote URL path, eg. `a/b`
+// can not point to a different repository.
+func pathSegment(name string) (string, error) {
+	if name == "" || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid owner or repository name %q", name)
+	}
+	return url.PathEscape(name), nil
+}
+
+func (s *service) remoteFor(owner, repo string) (string, error) {
+	o, err := pathSegment(owner)
+	if err != nil {
+		return "", err
+	}
+	r, err := pathSegment(repo)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	err = s.remoteURL.Execute(&buf, map[string]string{
+		"Owner": o,
+		"Repo":  r,
+	})
+	return buf.String(), err
+}
+
+func (s *service) lockFor(remote string) *sync.Mutex {
+	l, _ := s.locks.LoadOrStore(remote, &sync.Mutex{})
+	return l.(*sync.Mutex)
+}
+
+// mirrorFor returns a bare partial clone of remote which is reused across requests.
+func (s *service) mirrorFor(ctx context.Context, remote string) (*git, error) {
+	sum := sha256.Sum256([]byte(remote))
+	g := &git{
+		dir: filepath.Join(s.cacheDir, fmt.Sprintf("%s.git", hex.EncodeToString(sum[:]))),
+	}
+	if len(s.authHeader) > 0 {
+		g.config = append(g.config, fmt.Sprintf("http.extraHeader=%s", s.authHeader))
+	}
+	if _, err := os.Stat(g.dir); err == nil {
+		return g, nil
+	}
+	tmp, err := os.MkdirTemp(s.cacheDir, "mirror-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	bare := &git{dir: tmp}
+	for _, args := range [][]string{
+		{"init", "--quiet", "--bare"},
+		{"remote", "add", remoteName, remote},
+		{"config", fmt.Sprintf("remote.%s.promisor", remoteName), "true"},
+		{"config", fmt.Sprintf("remote.%s.partialclonefilter", remoteName), "blob:none"},
+	} {
+		if _, err := bare.run(ctx, args...); err != nil {
+			return nil, err
+		}
+	}
+	return g, os.Rename(tmp, g.dir)
+}
+
+// checkRef rejects a ref which is not a valid ref name, eg. `--upload-pack=...`, since it comes from a webhook and is
+// passed to git.
+func checkRef(ctx context.Context, ref string) error {
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid ref %q", ref)
+	}
+	if _, err := (&git{}).run(ctx, "check-ref-format", "--allow-onelevel", ref); err != nil {
+		return fmt.Errorf("invalid ref %q: %w", ref, err)
+	}
+	return nil
+}
+
+// commitFor returns a commit for ref, fetching only the commit itself when it is not cached yet.
+func (s *service) commitFor(ctx context.Context, g *git, ref string) (string, error) {
+	if vcspipelineconfig.IsCommitSHA(ref) {
+		if g.exists(ctx, fmt.Sprintf("%s^{commit}", ref)) {
+			return ref, nil
+		}
+	} else if err := checkRef(ctx, ref); err != nil {
+		return "", err
+	}
+	_, err := g.run(ctx, "fetch", "--quiet", "--no-tags", "--depth=1", "--filter=blob:none",
+		"--end-of-options", remoteName, ref)
+	if err != nil {
+		return "", err
+	}
+	out, err := g.run(ctx, "rev-parse", "--verify", "FETCH_HEAD^{commit}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (s *service) contentFunc(g *git, commit string) vcspipelineconfig.ContentFunc {
+	return func(ctx context.Context, path string) ([]byte, []string, error) {
+		logger := logging.FromContext(ctx)
+		object := fmt.Sprintf("%s:%s", commit, path)
+		typ, err := g.run(ctx, "cat-file", "-t", object)
+		if err != nil {
+			logger.Errorw("Service failed to fetch config", zap.String("path", path), zap.Error(err))
+			if missingPath(err) && g.exists(ctx, fmt.Sprintf("%s^{tree}", commit)) {
+				err = fmt.Errorf("%w: %w", vcspipelineconfig.ErrNotFound, err)
+			}
+			return nil, nil, err
+		}
+		if strings.TrimSpace(string(typ)) != "tree" {
+			content, err := g.run(ctx, "cat-file", "blob", object)
+			if err != nil {
+				return nil, nil, err
+			}
+			logger.Debugf("config file content %s: %s", path, content)
+			return content, nil, nil
+		}
+		out, err := g.run(ctx, "ls-tree", object)
+		if err != nil {
+			return nil, nil, err
+		}
+		dir := []string{}
+		for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			// Each line is `<mode> SP <type> SP <object> TAB <file>`.
+			info, name, ok := strings.Cut(line, "\t")
+			if ok && strings.Contains(info, " blob ") {
+				dir = append(dir, name)
+			}
+		}
+		return nil, dir, nil
+	}
+}
+
+func (s *service) Get(ctx context.Context, owner, repo, ref, path string) (*pipelineconfig.Config, error) {
+	logger := logging.FromContext(ctx)
+	logger = logger.With(
+		zap.String("owner", owner),
+		zap.String("repository", repo),
+	)
+	logger.Infow("Service started fetch config")
+	remote, err := s.remoteFor(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	l := s.lockFor(remote)
+	l.Lock()
+	defer l.Unlock()
+	g, err := s.mirrorFor(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := s.commitFor(ctx, g, ref)
+	if err != nil {
+		logger.Errorw("Service failed to fetch ref", zap.String("ref", ref), zap.Error(err))
+		return nil, err
+	}
+	return vcspipelineconfig.ReadConfig(logging.WithLogger(ctx, logger), path, s.contentFunc(g, commit))
+}
+
+// NewService creates a service which fetches config from remoteURL, a template of the repository URL
+// with `.Owner` and `.Repo` fields, eg. `https://git.example.com/{{ .Owner }}/{{ .Repo }}.git`.
+// Fetched objects are kept in cacheDir; authHeader, if any, is sent with each HTTP request.
+func NewService(
+	remoteURL string,
+	cacheDir string,
+	authHeader string,
+) (vcspipelineconfig.Service, error) {
+	t, err := template.New("remote").Option("missingkey=error").Parse(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(cacheDir, 0o700)
+	if err != nil {
+		return nil, err
+	}
+	return &service{
+		remoteURL:  t,
+		cacheDir:   cacheDir,
+		authHeader: authHeader,
+	}, nil
+}
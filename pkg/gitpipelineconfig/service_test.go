@@ -0,0 +1,184 @@
+package gitpipelineconfig
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig/vcspipelineconfigtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	out, err := (&git{dir: dir}).run(context.TODO(), args...)
+	assert.Nil(t, err)
+	return strings.TrimSpace(string(out))
+}
+
+// newTestRemote creates a bare repository with files and returns its root and the commit.
+func newTestRemote(t *testing.T, repository *vcspipelineconfigtest.Repository) (string, string) {
+	root := t.TempDir()
+	work := t.TempDir()
+	for path, content := range repository.Files {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(work, path)), 0o700))
+		assert.Nil(t, os.WriteFile(filepath.Join(work, path), []byte(content), 0o600))
+	}
+	assert.Nil(t, os.WriteFile(filepath.Join(work, "README.md"), []byte(repository.Repo), 0o600))
+	runGit(t, work, "init", "--quiet")
+	runGit(t, work, "add", ".")
+	runGit(t, work, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "init")
+	commit := runGit(t, work, "rev-parse", "HEAD")
+	bare := filepath.Join(root, repository.Owner, fmt.Sprintf("%s.git", repository.Repo))
+	runGit(t, "", "clone", "--quiet", "--bare", work, bare)
+	runGit(t, bare, "config", "uploadpack.allowFilter", "true")
+	runGit(t, bare, "config", "uploadpack.allowAnySHA1InWant", "true")
+	return root, commit
+}
+
+func newTestServer(t *testing.T, root, token string, requests *int32) *httptest.Server {
+	execPath := runGit(t, "", "--exec-path")
+	backend := &cgi.Handler{
+		Path: filepath.Join(execPath, "git-http-backend"),
+		Env: []string{
+			fmt.Sprintf("GIT_PROJECT_ROOT=%s", root),
+			"GIT_HTTP_EXPORT_ALL=1",
+		},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if _, password, ok := r.BasicAuth(); !ok || password != token {
+			w.Header().Set("WWW-Authenticate", `Basic realm="git"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		backend.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func authHeader(token string) string {
+	return fmt.Sprintf("Authorization: Basic %s", base64.StdEncoding.EncodeToString([]byte("git:"+token)))
+}
+
+func newTestService(t *testing.T, repository *vcspipelineconfigtest.Repository, token string) vcspipelineconfig.Service {
+	root, commit := newTestRemote(t, repository)
+	repository.Ref = commit
+	var requests int32
+	srv := newTestServer(t, root, repository.Token, &requests)
+	svc, err := NewService(srv.URL+"/{{ .Owner }}/{{ .Repo }}.git", t.TempDir(), authHeader(token))
+	assert.Nil(t, err)
+	return svc
+}
+
+func TestService(t *testing.T) {
+	vcspipelineconfigtest.Run(t, newTestService)
+}
+
+func TestService_Get_Cached(t *testing.T) {
+	repository := &vcspipelineconfigtest.Repository{
+		Owner: vcspipelineconfigtest.Owner,
+		Repo:  vcspipelineconfigtest.Repo,
+		Token: vcspipelineconfigtest.Token,
+		Files: map[string]string{
+			vcspipelineconfigtest.ConfigFile: "triggers: []",
+		},
+	}
+	root, commit := newTestRemote(t, repository)
+	var requests int32
+	srv := newTestServer(t, root, repository.Token, &requests)
+	svc, err := NewService(srv.URL+"/{{ .Owner }}/{{ .Repo }}.git", t.TempDir(), authHeader(repository.Token))
+	assert.Nil(t, err)
+	_, err = svc.Get(context.TODO(), repository.Owner, repository.Repo, commit, vcspipelineconfigtest.ConfigFile)
+	assert.Nil(t, err)
+	n := atomic.LoadInt32(&requests)
+	assert.NotZero(t, n)
+	_, err = svc.Get(context.TODO(), repository.Owner, repository.Repo, commit, vcspipelineconfigtest.ConfigFile)
+	assert.Nil(t, err)
+	assert.Equal(t, n, atomic.LoadInt32(&requests))
+}
+
+func TestService_Get_Branch(t *testing.T) {
+	repository := &vcspipelineconfigtest.Repository{
+		Owner: vcspipelineconfigtest.Owner,
+		Repo:  vcspipelineconfigtest.Repo,
+		Token: vcspipelineconfigtest.Token,
+		Files: map[string]string{
+			vcspipelineconfigtest.ConfigFile: "triggers: [{name: main}]",
+		},
+	}
+	root, _ := newTestRemote(t, repository)
+	var requests int32
+	srv := newTestServer(t, root, repository.Token, &requests)
+	svc, err := NewService(srv.URL+"/{{ .Owner }}/{{ .Repo }}.git", t.TempDir(), authHeader(repository.Token))
+	assert.Nil(t, err)
+	cfg, err := svc.Get(context.TODO(), repository.Owner, repository.Repo, "HEAD", vcspipelineconfigtest.ConfigFile)
+	assert.Nil(t, err)
+	assert.Len(t, cfg.Triggers, 1)
+}
+
+func TestService_remoteFor(t *testing.T) {
+	svc, err := NewService("https://git.example.com/{{ .Owner }}/{{ .Repo }}.git", t.TempDir(), "")
+	assert.Nil(t, err)
+	s := svc.(*service)
+	remote, err := s.remoteFor("my org", "foo/../bar")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://git.example.com/my%20org/foo%2F..%2Fbar.git", remote)
+	for _, name := range []string{"", ".", ".."} {
+		_, err = s.remoteFor(name, "bar")
+		assert.NotNil(t, err, name)
+		_, err = s.remoteFor("foo", name)
+		assert.NotNil(t, err, name)
+	}
+}
+
+func TestService_contentFunc_NotFound(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--quiet", "--bare")
+	tree := runGit(t, dir, "mktree")
+	commit := runGit(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit-tree", tree, "-m", "init")
+	s := &service{}
+	_, _, err := s.contentFunc(&git{dir: dir}, commit)(context.TODO(), vcspipelineconfigtest.ConfigFile)
+	assert.ErrorIs(t, err, vcspipelineconfig.ErrNotFound)
+	// A missing commit is not a missing config.
+	_, _, err = s.contentFunc(&git{dir: dir}, strings.Repeat("0", 40))(context.TODO(), vcspipelineconfigtest.ConfigFile)
+	assert.NotNil(t, err)
+	assert.NotErrorIs(t, err, vcspipelineconfig.ErrNotFound)
+}
+
+func TestService_Get_OptionRef(t *testing.T) {
+	repository := &vcspipelineconfigtest.Repository{
+		Owner: vcspipelineconfigtest.Owner,
+		Repo:  vcspipelineconfigtest.Repo,
+		Token: vcspipelineconfigtest.Token,
+		Files: map[string]string{
+			vcspipelineconfigtest.ConfigFile: "triggers: []",
+		},
+	}
+	root, _ := newTestRemote(t, repository)
+	var requests int32
+	srv := newTestServer(t, root, repository.Token, &requests)
+	svc, err := NewService(srv.URL+"/{{ .Owner }}/{{ .Repo }}.git", t.TempDir(), authHeader(repository.Token))
+	assert.Nil(t, err)
+	pwned := filepath.Join(t.TempDir(), "pwned")
+	for _, ref := range []string{
+		fmt.Sprintf("--upload-pack=touch %s; git-upload-pack", pwned),
+		"main..HEAD",
+		"refs/heads/ma in",
+	} {
+		_, err = svc.Get(context.TODO(), repository.Owner, repository.Repo, ref, vcspipelineconfigtest.ConfigFile)
+		assert.ErrorContains(t, err, "invalid ref", ref)
+		assert.NotErrorIs(t, err, vcspipelineconfig.ErrNotFound, ref)
+	}
+	_, err = os.Stat(pwned)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
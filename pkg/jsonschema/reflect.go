@@ -0,0 +1,139 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	timeType        = reflect.TypeOf(time.Time{})
+)
+
+// Reflector generates a schema of a Go type from its `json` tags. Named structs are put in `$defs`, and
+// properties which are not declared are not allowed.
+type Reflector struct {
+	overrides map[reflect.Type]*Schema
+	defs      map[string]*Schema
+}
+
+// Override sets a schema of t, eg. for a type with custom JSON unmarshalling. Other types with custom
+// unmarshalling, except the root one, accept any value.
+func (r *Reflector) Override(t reflect.Type, s *Schema) {
+	r.overrides[t] = s
+}
+
+func defName(t reflect.Type) string {
+	return strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + t.Name()
+}
+
+func (r *Reflector) reflectType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if s, ok := r.overrides[t]; ok {
+		return s
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.reflectType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.reflectType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.reflectStruct(t)
+		}
+		name := defName(t)
+		if _, ok := r.defs[name]; !ok {
+			// Reserve the name first, since types may refer to themselves.
+			r.defs[name] = nil
+			r.defs[name] = r.reflectStruct(t)
+		}
+		return &Schema{Ref: fmt.Sprintf("#/$defs/%s", name)}
+	default:
+		return &Schema{}
+	}
+}
+
+func (r *Reflector) reflectFields(t reflect.Type, props map[string]*Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			// Fields of an embedded struct are inlined, and shallower fields win like in encoding/json.
+			embedded := map[string]*Schema{}
+			r.reflectFields(ft, embedded)
+			for k, v := range embedded {
+				if _, ok := props[k]; !ok {
+					props[k] = v
+				}
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = r.reflectType(f.Type)
+	}
+}
+
+func (r *Reflector) reflectStruct(t reflect.Type) *Schema {
+	props := map[string]*Schema{}
+	r.reflectFields(t, props)
+	return &Schema{
+		Type:                 "object",
+		Properties:           props,
+		AdditionalProperties: false,
+	}
+}
+
+// Reflect returns a schema of v, which is reflected from its fields even if it has custom unmarshalling.
+func (r *Reflector) Reflect(v interface{}) *Schema {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	s := r.reflectStruct(t)
+	s.Schema = Draft
+	s.Defs = r.defs
+	return s
+}
+
+func NewReflector() *Reflector {
+	return &Reflector{
+		overrides: map[reflect.Type]*Schema{},
+		defs:      map[string]*Schema{},
+	}
+}
@@ -0,0 +1,29 @@
+package kubepipelineconfig
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+)
+
+const IncludeScheme = "configmap"
+
+// NewIncludeLoader returns a loader of includes like `configmap://namespace/name`, which rejects ConfigMaps out of
+// namespaces, so configs of any repository can not read ConfigMaps of other tenants.
+func NewIncludeLoader(service Service, namespaces []string) pipelineconfig.IncludeLoader {
+	return func(ctx context.Context, u *url.URL) (*pipelineconfig.Config, error) {
+		name := strings.Trim(u.Path, "/")
+		if u.Host == "" || name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("include %s must look like %s://namespace/name", u, IncludeScheme)
+		}
+		if !slices.Contains(namespaces, u.Host) {
+			return nil, fmt.Errorf("%w: namespace %s is not one of [%s]", pipelineconfig.ErrIncludeNotAllowed, u.Host,
+				strings.Join(namespaces, ", "))
+		}
+		return service.Get(ctx, u.Host, name)
+	}
+}
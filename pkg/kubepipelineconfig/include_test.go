@@ -0,0 +1,50 @@
+package kubepipelineconfig
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNewIncludeLoader(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "bar"},
+		Data: map[string]string{
+			"config.yaml": "triggers: [{name: main}]",
+		},
+	})
+	load := NewIncludeLoader(NewService(kubeClient), []string{"foo"})
+	u, err := url.Parse("configmap://foo/bar")
+	assert.Nil(t, err)
+	cfg, err := load(context.TODO(), u)
+	assert.Nil(t, err)
+	assert.Len(t, cfg.Triggers, 1)
+	u, err = url.Parse("configmap://foo/baz")
+	assert.Nil(t, err)
+	_, err = load(context.TODO(), u)
+	assert.NotNil(t, err)
+	u, err = url.Parse("configmap://foo")
+	assert.Nil(t, err)
+	_, err = load(context.TODO(), u)
+	assert.NotNil(t, err)
+}
+
+func TestNewIncludeLoader_NotAllowed(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "bar"},
+		Data: map[string]string{
+			"config.yaml": "triggers: [{name: main}]",
+		},
+	})
+	load := NewIncludeLoader(NewService(kubeClient), []string{"foo"})
+	u, err := url.Parse("configmap://kube-system/bar")
+	assert.Nil(t, err)
+	_, err = load(context.TODO(), u)
+	assert.ErrorIs(t, err, pipelineconfig.ErrIncludeNotAllowed)
+}
@@ -23,6 +23,7 @@ var _ v1beta1.InterceptorInterface = (*interceptor)(nil)
 type interceptor struct {
 	service  Service
 	resolver pipelineresolver.Resolver
+	includes *pipelineconfig.IncludeResolver
 }
 
 func (i *interceptor) valueOf(ctx context.Context, meta *pipelineresolver.Metadata, expr string) (string, error) {
@@ -50,10 +51,12 @@ func (i *interceptor) Process(ctx context.Context, req *v1beta1.InterceptorReque
 		return interceptors.Fail(codes.InvalidArgument, "Request body is malformed")
 	}
 	meta := &pipelineresolver.Metadata{
-		Header:     req.Header,
-		Extensions: req.Extensions,
-		Params:     req.InterceptorParams,
-		Body:       body,
+		Header:         req.Header,
+		Extensions:     req.Extensions,
+		Params:         req.InterceptorParams,
+		Body:           body,
+		RequestURL:     rw.RequestURL(),
+		TriggerContext: req.Context,
 	}
 	ns, err := i.findParam(ctx, meta, nsParamKey)
 	if err != nil {
@@ -70,6 +73,11 @@ func (i *interceptor) Process(ctx context.Context, req *v1beta1.InterceptorReque
 		logger.Errorw("Interceptor failed to fetch config", zap.Error(err))
 		return interceptors.Fail(codes.Internal, "Unable to fetch config")
 	}
+	prevCfg, err = i.includes.Resolve(ctx, prevCfg)
+	if err != nil {
+		logger.Errorw("Interceptor failed to resolve config includes", zap.Error(err))
+		return interceptors.Fail(codes.Internal, "Unable to resolve config includes")
+	}
 	nextCfg, err := rw.MergeConfig(prevCfg)
 	if err != nil {
 		logger.Errorw("Interceptor failed to merge config", zap.Error(err))
@@ -94,9 +102,11 @@ func (i *interceptor) Process(ctx context.Context, req *v1beta1.InterceptorReque
 func NewInterceptor(
 	service Service,
 	resolver pipelineresolver.Resolver,
+	includes *pipelineconfig.IncludeResolver,
 ) v1beta1.InterceptorInterface {
 	return &interceptor{
 		service:  service,
 		resolver: resolver,
+		includes: includes,
 	}
 }
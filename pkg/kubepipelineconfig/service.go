@@ -32,6 +32,9 @@ type service struct {
 
 var _ Service = (*service)(nil)
 
+// watchedName is the value of `name` label of ConfigMaps which are watched for changes.
+var watchedName = fmt.Sprintf("%s-cm", pipelineconfig.ConfigKey)
+
 func keyFor(namespace string, name string) string {
 	return fmt.Sprintf("%s.%s", name, namespace)
 }
@@ -41,7 +44,7 @@ func (s *service) Start(ctx context.Context) error {
 	defer s.mutex.Unlock()
 	if s.watcher == nil {
 		labelSelector := labels.SelectorFromSet(labels.Set{
-			"name": fmt.Sprintf("%s-cm", pipelineconfig.ConfigKey),
+			"name": watchedName,
 		})
 		rw, err := watchext.NewRetryWatcher("latest", &cache.ListWatch{
 			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
@@ -114,7 +117,11 @@ func (s *service) Get(ctx context.Context, namespace string, name string) (*pipe
 		if err != nil {
 			return nil, err
 		}
-		s.cache.Store(key, cfg)
+		// Other ConfigMaps are not updated by the watcher, so they are fetched every time.
+		if cm.Labels["name"] == watchedName {
+			s.cache.Store(key, cfg)
+		}
+		v = cfg
 	}
 	return v.(*pipelineconfig.Config), nil
 }
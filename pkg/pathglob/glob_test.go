@@ -0,0 +1,38 @@
+package pathglob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{"services/foo/**", "services/foo/main.go", true},
+		{"services/foo/**", "services/foo/pkg/api/api.go", true},
+		{"services/foo/**", "services/bar/main.go", false},
+		{"**/*.md", "README.md", true},
+		{"**/*.md", "docs/guide/index.md", true},
+		{"*.md", "docs/index.md", false},
+		{"services/*/go.mod", "services/foo/go.mod", true},
+		{"services/*/go.mod", "services/foo/bar/go.mod", false},
+		{"file?.txt", "file1.txt", true},
+		{"file[!0-9].txt", "file1.txt", false},
+		{"file[!0-9].txt", "filea.txt", true},
+		{"a+b/**", "a+b/c", true},
+	}
+	for _, tt := range tests {
+		m, err := Match(tt.pattern, tt.name)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.match, m, "%s ~ %s", tt.pattern, tt.name)
+	}
+}
+
+func TestMatch_Malformed(t *testing.T) {
+	_, err := Match("file[0-9.txt", "file1.txt")
+	assert.NotNil(t, err)
+}
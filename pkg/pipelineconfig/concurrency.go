@@ -0,0 +1,110 @@
+package pipelineconfig
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	v1pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+const (
+	// RepositoryKey is an extension key of the `owner/repo` of a webhook event, which VCS interceptors set.
+	RepositoryKey = "repository"
+	// ConcurrencyLabelKey labels PipelineRuns of a concurrency group with a hash of the repository and the group,
+	// since groups like `refs/heads/main` are not valid label values.
+	ConcurrencyLabelKey = "pipeline-config.tekton.dev/concurrency"
+	// ConcurrencyGroupAnnotationKey is the concurrency group of a PipelineRun.
+	ConcurrencyGroupAnnotationKey = "pipeline-config.tekton.dev/concurrency-group"
+	// CancelInProgressAnnotationKey marks PipelineRuns which cancel running PipelineRuns of their concurrency group
+	// before they are created.
+	CancelInProgressAnnotationKey = "pipeline-config.tekton.dev/cancel-in-progress"
+	// MaxConcurrentAnnotationKey is the maximum number of running PipelineRuns of the concurrency group of a
+	// PipelineRun, while other ones are pending.
+	MaxConcurrentAnnotationKey = "pipeline-config.tekton.dev/max-concurrent"
+)
+
+// Concurrency groups PipelineRuns of a trigger, eg. by a pull request, so a new event supersedes running ones, or by a
+// repository, so a limited number of them run at once.
+type Concurrency struct {
+	Group            string `json:"group,omitempty"`
+	CancelInProgress bool   `json:"cancelInProgress,omitempty"`
+	MaxConcurrent    int    `json:"maxConcurrent,omitempty"`
+}
+
+func (c *Concurrency) validate() error {
+	if c.Group == "" {
+		return FieldError("group", errors.New("is required"))
+	}
+	if c.MaxConcurrent < 0 {
+		return FieldError("maxConcurrent", errors.New("must not be negative"))
+	}
+	return nil
+}
+
+// group resolves the group expression, eg. `"pr-" + string(body.number)`.
+func (c *Concurrency) group(ctx context.Context, meta *pipelineresolver.Metadata) (string, error) {
+	r, err := pipelineresolver.FromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	v, err := r.ValueOf(ctx, meta, c.Group)
+	if err != nil {
+		return "", FieldError("group", err)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", FieldError("group", fmt.Errorf("unable to convert value from '%T' to 'string'", v))
+	}
+	return s, nil
+}
+
+// repository returns the repository of an event from the extension of a VCS interceptor, or from the body of GitHub,
+// Gitea, or GitLab events.
+func repository(meta *pipelineresolver.Metadata) string {
+	if s, ok := meta.Extensions[RepositoryKey].(string); ok && s != "" {
+		return s
+	}
+	for _, path := range [][]string{{"repository", "full_name"}, {"project", "path_with_namespace"}} {
+		m, ok := meta.Body[path[0]].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if s, ok := m[path[1]].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// apply labels PipelineRuns with the concurrency group, including pending ones which need other PipelineRuns.
+func (c *Concurrency) apply(ctx context.Context, meta *pipelineresolver.Metadata, prs []*v1pipeline.PipelineRun) error {
+	group, err := c.group(ctx, meta)
+	if err != nil {
+		return err
+	}
+	// Groups are scoped by repository, so pull requests with the same number of different repositories do not cancel
+	// each other.
+	sum := sha256.Sum256([]byte(repository(meta) + "\x00" + group))
+	for _, pr := range prs {
+		if pr.Labels == nil {
+			pr.Labels = map[string]string{}
+		}
+		if pr.Annotations == nil {
+			pr.Annotations = map[string]string{}
+		}
+		pr.Labels[ConcurrencyLabelKey] = hex.EncodeToString(sum[:8])
+		pr.Annotations[ConcurrencyGroupAnnotationKey] = group
+		if c.CancelInProgress {
+			pr.Annotations[CancelInProgressAnnotationKey] = "true"
+		}
+		if c.MaxConcurrent > 0 {
+			pr.Annotations[MaxConcurrentAnnotationKey] = strconv.Itoa(c.MaxConcurrent)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package pipelineconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_PipelineRuns_Concurrency(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	ctx := pipelineresolver.WithResolver(context.TODO(), r)
+	var cfg Config
+	err = cfg.UnmarshalYAMLStrict([]byte(`
+triggers:
+  - name: pr
+    filter: "true"
+    concurrency:
+      group: '"pr-" + string(body.number)'
+      cancelInProgress: true
+    pipelines:
+      - name: build
+      - name: deploy
+        needs: [build]
+  - name: push
+    filter: "true"
+    concurrency:
+      group: body.ref
+      maxConcurrent: 2
+    pipelines:
+      - name: build
+`))
+	assert.Nil(t, err)
+	prs, err := cfg.PipelineRuns(ctx, &pipelineresolver.Metadata{
+		Body: map[string]interface{}{"number": 42, "ref": "refs/heads/main"},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, prs, 3)
+	pr, deploy, push := prs[0], prs[1], prs[2]
+	assert.Len(t, pr.Labels[ConcurrencyLabelKey], 16)
+	assert.Equal(t, "pr-42", pr.Annotations[ConcurrencyGroupAnnotationKey])
+	assert.Equal(t, "true", pr.Annotations[CancelInProgressAnnotationKey])
+	assert.Equal(t, pr.Labels[ConcurrencyLabelKey], deploy.Labels[ConcurrencyLabelKey])
+	assert.NotEqual(t, pr.Labels[ConcurrencyLabelKey], push.Labels[ConcurrencyLabelKey])
+	assert.Equal(t, "refs/heads/main", push.Annotations[ConcurrencyGroupAnnotationKey])
+	assert.NotContains(t, push.Annotations, CancelInProgressAnnotationKey)
+	assert.Equal(t, "2", push.Annotations[MaxConcurrentAnnotationKey])
+}
+
+func TestConfig_Validate_Concurrency(t *testing.T) {
+	var cfg Config
+	err := cfg.UnmarshalYAMLStrict([]byte(`
+triggers:
+  - name: main
+    concurrency:
+      cancelInProgress: true
+`))
+	assert.Nil(t, err)
+	err = cfg.Validate()
+	var d *Diagnostic
+	assert.ErrorAs(t, err, &d)
+	assert.Equal(t, "triggers[0].concurrency.group", d.Path())
+	assert.EqualError(t, d.Err, "is required")
+}
+
+func TestConfig_PipelineRuns_Concurrency_Repository(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	ctx := pipelineresolver.WithResolver(context.TODO(), r)
+	var cfg Config
+	err = cfg.UnmarshalYAMLStrict([]byte(`
+triggers:
+  - name: pr
+    filter: "true"
+    concurrency:
+      group: '"pr-" + string(body.number)'
+    pipelines:
+      - name: build
+`))
+	assert.Nil(t, err)
+	label := func(meta *pipelineresolver.Metadata) string {
+		prs, err := cfg.PipelineRuns(ctx, meta)
+		assert.Nil(t, err)
+		return prs[0].Labels[ConcurrencyLabelKey]
+	}
+	body := func(repo string) map[string]interface{} {
+		return map[string]interface{}{"number": 42, "repository": map[string]interface{}{"full_name": repo}}
+	}
+
+	// Pull requests with the same number of different repositories are in different groups.
+	a := label(&pipelineresolver.Metadata{Body: body("org/a")})
+	assert.NotEqual(t, a, label(&pipelineresolver.Metadata{Body: body("org/b")}))
+	assert.Equal(t, a, label(&pipelineresolver.Metadata{
+		Body:       map[string]interface{}{"number": 42},
+		Extensions: map[string]interface{}{RepositoryKey: "org/a"},
+	}))
+}
@@ -1,9 +1,13 @@
 package pipelineconfig
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"reflect"
 
 	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelinemerge"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
@@ -16,18 +20,120 @@ import (
 
 const ConfigKey = "pipeline-config"
 
+const (
+	// ExpressionsImplicit resolves every value which is a valid expression, and uses others as literals.
+	ExpressionsImplicit = "implicit"
+	// ExpressionsExplicit resolves only expressions written as `${{ expr }}` or `$(cel: expr)`.
+	ExpressionsExplicit = "explicit"
+)
+
+const (
+	// ResolverCEL resolves values as CEL expressions.
+	ResolverCEL = "cel"
+	// ResolverTemplate resolves values as Go templates, eg. `{{ .body.ref }}`.
+	ResolverTemplate = "template"
+)
+
 var _ json.Unmarshaler = (*Config)(nil)
 var _ json.Marshaler = (*Config)(nil)
 
 type Config struct {
-	Defaults pipelinerun.PipelineRun `json:"defaults,omitempty" yaml:"defaults,omitempty"`
-	Triggers TriggerSlice            `json:"triggers,omitempty" yaml:"triggers,omitempty"`
+	Include     []string                `json:"include,omitempty" yaml:"include,omitempty"`
+	Resolver    string                  `json:"resolver,omitempty" yaml:"resolver,omitempty"`
+	Expressions string                  `json:"expressions,omitempty" yaml:"expressions,omitempty"`
+	Defaults    pipelinerun.PipelineRun `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	Triggers    TriggerSlice            `json:"triggers,omitempty" yaml:"triggers,omitempty"`
+}
+
+// modes returns the resolver and the expressions of a config, or empty ones if it has nothing to resolve, eg. an
+// empty config which other ones are merged into.
+func (c *Config) modes() (string, string) {
+	if c.Resolver == "" && c.Expressions == "" && len(c.Triggers) == 0 && reflect.ValueOf(c.Defaults).IsZero() {
+		return "", ""
+	}
+	resolver, expressions := c.Resolver, c.Expressions
+	if resolver == "" {
+		resolver = ResolverCEL
+	}
+	if expressions == "" {
+		expressions = ExpressionsImplicit
+	}
+	return resolver, expressions
 }
 
+// Merge merges s into c. Resolver and Expressions of a merged config apply to values of both, so configs of
+// different ones, eg. an explicit local config which includes an implicit one, are not merged.
 func (c *Config) Merge(s *Config) error {
+	resolver, expressions := c.modes()
+	sResolver, sExpressions := s.modes()
+	if resolver != "" && sResolver != "" {
+		if resolver != sResolver {
+			return FieldError("resolver", fmt.Errorf("`%s` differs from `%s` of a merged config", sResolver, resolver))
+		}
+		if expressions != sExpressions {
+			return FieldError("expressions", fmt.Errorf("`%s` differs from `%s` of a merged config",
+				sExpressions, expressions))
+		}
+	}
 	return mergo.Merge(c, s, pipelinemerge.DefaultOptions...)
 }
 
+// changedFilesVar is a variable of resolvers with files changed by an event.
+const changedFilesVar = "changedFiles"
+
+// NeedsChangedFiles reports whether any trigger has paths, or any value, eg. a filter or a param, refers to changed
+// files, so they have to be fetched. It is true if the resolver of the config, which is based on the resolver of ctx,
+// cannot tell.
+func (c *Config) NeedsChangedFiles(ctx context.Context) bool {
+	for _, t := range c.Triggers {
+		if len(t.Paths) > 0 || len(t.PathsIgnore) > 0 {
+			return true
+		}
+	}
+	ctx, err := c.ResolverContext(ctx)
+	if err != nil {
+		return true
+	}
+	r, err := pipelineresolver.FromContext(ctx)
+	if err != nil {
+		return true
+	}
+	ref, ok := r.(pipelineresolver.Referrer)
+	if !ok {
+		return true
+	}
+	buf, err := c.MarshalJSON()
+	if err != nil {
+		return true
+	}
+	var v interface{}
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return true
+	}
+	return refers(ref, v, changedFilesVar)
+}
+
+// refers walks values of a config, since any of them may be an expression.
+func refers(r pipelineresolver.Referrer, v interface{}, name string) bool {
+	switch v := v.(type) {
+	case string:
+		return r.Refers(v, name)
+	case []interface{}:
+		for _, i := range v {
+			if refers(r, i, name) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		for _, i := range v {
+			if refers(r, i, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (c *Config) toPipelineRun(ctx context.Context, meta *pipelineresolver.Metadata, p ...*pipelinerun.PipelineRun) (*v1pipeline.PipelineRun, error) {
 	tr := &pipelinerun.PipelineRun{}
 	err := tr.MergeAll(p...)
@@ -41,23 +147,76 @@ func (c *Config) toPipelineRun(ctx context.Context, meta *pipelineresolver.Metad
 	return tr.PipelineRun()
 }
 
+// ResolverContext replaces the resolver of ctx according to Resolver of the config, and wraps it according to
+// Expressions.
+func (c *Config) ResolverContext(ctx context.Context) (context.Context, error) {
+	switch c.Resolver {
+	case "", ResolverCEL:
+	case ResolverTemplate:
+		ctx = pipelineresolver.WithResolver(ctx, pipelineresolver.NewTemplateResolver())
+	default:
+		return nil, FieldError("resolver", fmt.Errorf("must be one of `%s` or `%s`, got `%s`",
+			ResolverCEL, ResolverTemplate, c.Resolver))
+	}
+	switch c.Expressions {
+	case "", ExpressionsImplicit:
+		return ctx, nil
+	case ExpressionsExplicit:
+		if c.Resolver == ResolverTemplate {
+			return nil, FieldError("expressions", fmt.Errorf("`%s` is not supported by `%s` resolver",
+				ExpressionsExplicit, ResolverTemplate))
+		}
+		r, err := pipelineresolver.FromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return pipelineresolver.WithResolver(ctx, pipelineresolver.NewExplicitResolver(r)), nil
+	default:
+		return nil, FieldError("expressions", fmt.Errorf("must be one of `%s` or `%s`, got `%s`",
+			ExpressionsImplicit, ExpressionsExplicit, c.Expressions))
+	}
+}
+
 func (c *Config) PipelineRuns(ctx context.Context, meta *pipelineresolver.Metadata) ([]*v1pipeline.PipelineRun, error) {
+	err := c.Validate()
+	if err != nil {
+		return nil, err
+	}
+	ctx, err = c.ResolverContext(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var prs []*v1pipeline.PipelineRun
-	for _, t := range c.Triggers {
-		ok, err := t.Filter.Match(ctx, meta)
+	for i, t := range c.Triggers {
+		ok, err := t.Match(ctx, meta)
 		if err != nil {
-			return nil, err
+			return nil, ItemError("triggers", i, t.Name, err)
 		}
 		if !ok {
 			continue
 		}
-		for _, p := range t.Pipelines {
+		var tprs []*v1pipeline.PipelineRun
+		for j, p := range t.Pipelines {
 			pr, err := c.toPipelineRun(ctx, meta, &c.Defaults, &t.Defaults, &p)
+			if err != nil {
+				return nil, ItemError("triggers", i, t.Name, ItemError("pipelines", j, p.Name, err))
+			}
+			stampIdempotencyKey(meta, &t, p.Name, pr)
+			tprs = append(tprs, pr)
+		}
+		if t.Concurrency != nil {
+			err = t.Concurrency.apply(ctx, meta, tprs)
+			if err != nil {
+				return nil, ItemError("triggers", i, t.Name, FieldError("concurrency", err))
+			}
+		}
+		if t.hasNeeds() {
+			err = groupPipelineRuns(groupID(meta, i, &t), &t, tprs)
 			if err != nil {
 				return nil, err
 			}
-			prs = append(prs, pr)
 		}
+		prs = append(prs, tprs...)
 	}
 	return prs, nil
 }
@@ -80,7 +239,34 @@ func (c *Config) MarshalJSON() ([]byte, error) {
 }
 
 func (c *Config) UnmarshalYAML(data []byte) error {
-	return yaml.UnmarshalStrict(data, &c)
+	buf, err := yaml.YAMLToJSONStrict(data)
+	if err != nil {
+		return err
+	}
+	err = c.UnmarshalJSON(buf)
+	if err != nil {
+		return locateDecodeError(data, err)
+	}
+	return nil
+}
+
+// UnmarshalYAMLStrict rejects unknown fields at any depth and duplicate keys, unlike UnmarshalYAML, which does not
+// pass strictness through UnmarshalJSON of Config, so existing configs with unknown fields keep working.
+func (c *Config) UnmarshalYAMLStrict(data []byte) error {
+	buf, err := yaml.YAMLToJSONStrict(data)
+	if err != nil {
+		return err
+	}
+	type TriggerConfigJSON Config
+	var t TriggerConfigJSON
+	d := json.NewDecoder(bytes.NewReader(buf))
+	d.DisallowUnknownFields()
+	err = d.Decode(&t)
+	if err != nil && err != io.EOF {
+		return locateDecodeError(data, err)
+	}
+	*c = Config(t)
+	return nil
 }
 
 func (c *Config) UnmarshalConfigMapYAML(cm *v1.ConfigMap) error {
@@ -1,9 +1,11 @@
 package pipelineconfig
 
 import (
+	"context"
 	"os"
 	"testing"
 
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -29,3 +31,137 @@ func TestConfig_UnmarshalYAML_MarshalJSON(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, b)
 }
+
+func TestConfig_UnmarshalYAMLStrict(t *testing.T) {
+	for _, s := range []string{
+		"trigger: []",
+		"triggers: [{name: main, filters: 'true'}]",
+		"triggers: [{name: main, pipelines: [{name: foo, params: [{name: foo, valeu: bar}]}]}]",
+		"defaults: {metadata: {label: {foo: bar}}}",
+		"triggers: []\ntriggers: []",
+	} {
+		var cfg Config
+		err := cfg.UnmarshalYAMLStrict([]byte(s))
+		assert.NotNil(t, err, s)
+	}
+	var cfg Config
+	err := cfg.UnmarshalYAMLStrict([]byte(""))
+	assert.Nil(t, err)
+	buf, err := os.ReadFile("testdata/config.yaml")
+	assert.Nil(t, err)
+	err = cfg.UnmarshalYAMLStrict(buf)
+	assert.Nil(t, err)
+	assert.Len(t, cfg.Triggers, 2)
+}
+
+func TestConfig_PipelineRuns_ExplicitExpressions(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	ctx := pipelineresolver.WithResolver(context.TODO(), r)
+	meta := &pipelineresolver.Metadata{
+		Body: map[string]interface{}{"ref": "refs/heads/main"},
+	}
+	var cfg Config
+	err = cfg.UnmarshalYAMLStrict([]byte(`
+expressions: explicit
+triggers:
+  - name: main
+    filter: body.ref == "refs/heads/main"
+    pipelines:
+      - name: build
+        params:
+          - name: literal
+            value: body.ref
+          - name: expr
+            value: ${{ body.ref }}
+`))
+	assert.Nil(t, err)
+	prs, err := cfg.PipelineRuns(ctx, meta)
+	assert.Nil(t, err)
+	assert.Len(t, prs, 1)
+	assert.Equal(t, "body.ref", prs[0].Spec.Params[0].Value.StringVal)
+	assert.Equal(t, "refs/heads/main", prs[0].Spec.Params[1].Value.StringVal)
+
+	cfg.Expressions = "heuristic"
+	_, err = cfg.PipelineRuns(ctx, meta)
+	var d *Diagnostic
+	assert.ErrorAs(t, err, &d)
+	assert.Equal(t, "expressions", d.Path())
+}
+
+func TestConfig_PipelineRuns_TemplateResolver(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	ctx := pipelineresolver.WithResolver(context.TODO(), r)
+	meta := &pipelineresolver.Metadata{
+		Body: map[string]interface{}{
+			"ref":        "refs/heads/main",
+			"repository": map[string]interface{}{"name": "tekton-toolbox"},
+		},
+	}
+	var cfg Config
+	err = cfg.UnmarshalYAMLStrict([]byte(`
+resolver: template
+triggers:
+  - name: main
+    filter: '{{ eq .body.ref "refs/heads/main" }}'
+    pipelines:
+      - name: build
+        params:
+          - name: literal
+            value: body.ref
+          - name: repo
+            value: '{{ .body.repository.name | upper }}'
+  - name: tag
+    filter: '{{ hasPrefix "refs/tags/" .body.ref }}'
+    pipelines:
+      - name: release
+`))
+	assert.Nil(t, err)
+	prs, err := cfg.PipelineRuns(ctx, meta)
+	assert.Nil(t, err)
+	assert.Len(t, prs, 1)
+	assert.Equal(t, "body.ref", prs[0].Spec.Params[0].Value.StringVal)
+	assert.Equal(t, "TEKTON-TOOLBOX", prs[0].Spec.Params[1].Value.StringVal)
+
+	cfg.Expressions = ExpressionsExplicit
+	_, err = cfg.PipelineRuns(ctx, meta)
+	var d *Diagnostic
+	assert.ErrorAs(t, err, &d)
+	assert.Equal(t, "expressions", d.Path())
+
+	cfg.Resolver = "jsonnet"
+	_, err = cfg.PipelineRuns(ctx, meta)
+	assert.ErrorAs(t, err, &d)
+	assert.Equal(t, "resolver", d.Path())
+}
+
+func TestConfig_Merge_Modes(t *testing.T) {
+	tests := []struct {
+		dst  string
+		src  string
+		path string
+	}{
+		{dst: "", src: "expressions: explicit"},
+		{dst: "expressions: explicit\ntriggers: [{name: main}]", src: "expressions: explicit"},
+		{dst: "triggers: [{name: main}]", src: "expressions: implicit\nresolver: cel"},
+		{dst: "triggers: [{name: main}]", src: "expressions: explicit", path: "expressions"},
+		{dst: "expressions: explicit", src: "triggers: [{name: main}]", path: "expressions"},
+		{dst: "defaults: {name: main}", src: "resolver: template", path: "resolver"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dst+"+"+tt.src, func(t *testing.T) {
+			var dst, src Config
+			assert.Nil(t, dst.UnmarshalYAMLStrict([]byte(tt.dst)))
+			assert.Nil(t, src.UnmarshalYAMLStrict([]byte(tt.src)))
+			err := dst.Merge(&src)
+			if tt.path == "" {
+				assert.Nil(t, err)
+				return
+			}
+			var d *Diagnostic
+			assert.ErrorAs(t, err, &d)
+			assert.Equal(t, tt.path, d.Path())
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package pipelineconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/jsonschema"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelinerun"
+	"gopkg.in/yaml.v3"
+)
+
+type pathElem struct {
+	// key is a name of a field, or empty for an item of a sequence.
+	key   string
+	index int
+	// name is a name of an item, which identifies it better than index, since items are merged by name.
+	name string
+}
+
+// Diagnostic is an error of a config field, eg. `triggers[2].filter`, with its position in YAML source.
+type Diagnostic struct {
+	Err error
+	// Line and Column are a position of the field, or of an issue in its expression, starting at 1, or zero if
+	// unknown.
+	Line   int
+	Column int
+	path   []pathElem
+}
+
+func (d *Diagnostic) Path() string {
+	var b strings.Builder
+	for _, e := range d.path {
+		if e.key == "" {
+			fmt.Fprintf(&b, "[%d]", e.index)
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(e.key)
+	}
+	return b.String()
+}
+
+func (d *Diagnostic) Error() string {
+	if d.Line > 0 {
+		return fmt.Sprintf("%d:%d: %s: %v", d.Line, d.Column, d.Path(), d.Err)
+	}
+	return fmt.Sprintf("%s: %v", d.Path(), d.Err)
+}
+
+func (d *Diagnostic) Unwrap() error {
+	return d.Err
+}
+
+func withPath(err error, elems ...pathElem) error {
+	var d *Diagnostic
+	if errors.As(err, &d) {
+		d.path = append(elems, d.path...)
+		return err
+	}
+	// Field errors of pipelines carry their own path, which is relative to the pipeline.
+	var fe *pipelinerun.FieldError
+	if errors.As(err, &fe) {
+		for _, e := range fe.Path {
+			elems = append(elems, pathElem{key: e.Key, index: e.Index, name: e.Name})
+		}
+		return &Diagnostic{Err: fe.Err, path: elems}
+	}
+	return &Diagnostic{Err: err, path: elems}
+}
+
+// FieldError returns err as a Diagnostic of field key, or prepends key to the path of a Diagnostic.
+func FieldError(key string, err error) error {
+	return withPath(err, pathElem{key: key})
+}
+
+// ItemError returns err as a Diagnostic of an item of field key, or prepends the item to the path of a Diagnostic.
+func ItemError(key string, index int, name string, err error) error {
+	return withPath(err, pathElem{key: key}, pathElem{index: index, name: name})
+}
+
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return resolveAlias(n.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// exprPosition maps a position of an expression issue to a position in YAML source, if the expression can be
+// found there verbatim.
+func exprPosition(src []string, n *yaml.Node, line, column int) (int, int, bool) {
+	lines := strings.Split(n.Value, "\n")
+	if line < 1 || line > len(lines) {
+		return 0, 0, false
+	}
+	offset := column
+	for _, l := range lines[:line-1] {
+		offset += len(l) + 1
+	}
+	switch n.Style {
+	case yaml.LiteralStyle, yaml.FoldedStyle:
+		// Lines of a block scalar may be folded, so they are searched in the value one by one.
+		p := 0
+		for i := n.Line; i < len(src) && p < len(n.Value); i++ {
+			s := strings.TrimLeft(src[i], " ")
+			if s == "" {
+				continue
+			}
+			start := strings.Index(n.Value[p:], s)
+			if start < 0 {
+				return 0, 0, false
+			}
+			start += p
+			if offset < start+len(s) {
+				return i + 1, len(src[i]) - len(s) + max(offset-start, 0) + 1, true
+			}
+			p = start + len(s)
+		}
+		return 0, 0, false
+	case yaml.DoubleQuotedStyle, yaml.SingleQuotedStyle:
+		if len(lines) > 1 {
+			return 0, 0, false
+		}
+		return n.Line, n.Column + offset + 1, true
+	default:
+		if len(lines) > 1 {
+			return 0, 0, false
+		}
+		return n.Line, n.Column + offset, true
+	}
+}
+
+// Locate finds the field of d in YAML data, and sets its position. Items are found by name if it is known, since
+// they may have a different index in a config before merging.
+func (d *Diagnostic) Locate(data []byte) bool {
+	var doc yaml.Node
+	if yaml.Unmarshal(data, &doc) != nil || len(doc.Content) == 0 {
+		return false
+	}
+	n := resolveAlias(doc.Content[0])
+	path := make([]pathElem, len(d.path))
+	copy(path, d.path)
+	for i, e := range path {
+		switch {
+		case e.key != "" && n.Kind == yaml.MappingNode:
+			n = mappingValue(n, e.key)
+		case e.key == "" && n.Kind == yaml.SequenceNode && e.name != "":
+			var item *yaml.Node
+			for j, c := range n.Content {
+				c = resolveAlias(c)
+				if c.Kind != yaml.MappingNode {
+					continue
+				}
+				if v := mappingValue(c, "name"); v != nil && v.Value == e.name {
+					item = c
+					path[i].index = j
+					break
+				}
+			}
+			n = item
+		case e.key == "" && n.Kind == yaml.SequenceNode && e.index < len(n.Content):
+			n = resolveAlias(n.Content[e.index])
+		default:
+			n = nil
+		}
+		if n == nil {
+			return false
+		}
+	}
+	d.path = path
+	d.Line, d.Column = n.Line, n.Column
+	var e *pipelineresolver.ExprInvalidError
+	if errors.As(d.Err, &e) && n.Kind == yaml.ScalarNode {
+		if line, column, ok := exprPosition(strings.Split(string(data), "\n"), n, e.Line, e.Column); ok {
+			d.Line, d.Column = line, column
+		}
+	}
+	return true
+}
+
+// schemaIssue is a field which does not conform to a schema, either an unknown one, or one of a wrong type.
+type schemaIssue struct {
+	diagnostic *Diagnostic
+	field      string
+	unknown    bool
+}
+
+type schemaWalker struct {
+	root   *jsonschema.Schema
+	issues []schemaIssue
+}
+
+func (w *schemaWalker) issue(n *yaml.Node, path []pathElem, unknown bool) {
+	var field string
+	for _, e := range path {
+		if e.key != "" {
+			field = e.key
+		}
+	}
+	w.issues = append(w.issues, schemaIssue{
+		diagnostic: &Diagnostic{Line: n.Line, Column: n.Column, path: path},
+		field:      field,
+		unknown:    unknown,
+	})
+}
+
+func scalarIs(n *yaml.Node, tags ...string) bool {
+	if n.Kind != yaml.ScalarNode {
+		return false
+	}
+	for _, t := range tags {
+		if n.Tag == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *schemaWalker) walk(s *jsonschema.Schema, n *yaml.Node, path []pathElem) {
+	if s.Ref != "" {
+		s = w.root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+	}
+	n = resolveAlias(n)
+	if s == nil || n.Tag == "!!null" || len(s.OneOf) > 0 {
+		return
+	}
+	switch s.Type {
+	case "object":
+		if n.Kind != yaml.MappingNode {
+			w.issue(n, path, false)
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k := n.Content[i]
+			next := append(path[:len(path):len(path)], pathElem{key: k.Value})
+			ps, ok := s.Properties[k.Value]
+			if !ok {
+				switch ap := s.AdditionalProperties.(type) {
+				case *jsonschema.Schema:
+					ps = ap
+				case bool:
+					if !ap {
+						w.issue(k, next, true)
+					}
+				}
+			}
+			if ps != nil {
+				w.walk(ps, n.Content[i+1], next)
+			}
+		}
+	case "array":
+		if n.Kind != yaml.SequenceNode {
+			w.issue(n, path, false)
+			return
+		}
+		for i, c := range n.Content {
+			w.walk(s.Items, c, append(path[:len(path):len(path)], pathElem{index: i}))
+		}
+	case "string":
+		if !scalarIs(n, "!!str", "!!timestamp") {
+			w.issue(n, path, false)
+		}
+	case "boolean":
+		if !scalarIs(n, "!!bool") {
+			w.issue(n, path, false)
+		}
+	case "integer":
+		if !scalarIs(n, "!!int") {
+			w.issue(n, path, false)
+		}
+	case "number":
+		if !scalarIs(n, "!!int", "!!float") {
+			w.issue(n, path, false)
+		}
+	}
+}
+
+// locateDecodeError finds a field which fails decoding of YAML data with err, since JSON decoding errors do not have
+// a position in YAML source, and keys are sorted by then.
+func locateDecodeError(data []byte, err error) error {
+	var field string
+	var unknown bool
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &te) {
+		field = te.Field[strings.LastIndex(te.Field, ".")+1:]
+	} else if s, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		field, _ = strconv.Unquote(s)
+		unknown = true
+	}
+	var doc yaml.Node
+	if field == "" || yaml.Unmarshal(data, &doc) != nil || len(doc.Content) == 0 {
+		return err
+	}
+	root := JSONSchema()
+	w := &schemaWalker{root: root}
+	w.walk(root, doc.Content[0], nil)
+	for _, i := range w.issues {
+		if i.field == field && i.unknown == unknown {
+			i.diagnostic.Err = err
+			return i.diagnostic
+		}
+	}
+	return err
+}
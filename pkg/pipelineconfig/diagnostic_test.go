@@ -0,0 +1,130 @@
+package pipelineconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_UnmarshalYAMLStrict_Diagnostic(t *testing.T) {
+	tests := []struct {
+		yaml   string
+		path   string
+		line   int
+		column int
+	}{
+		{"triggers:\n  - name: pr\n  - name: main\n    filters: 'true'\n", "triggers[1].filters", 4, 5},
+		{"triggers:\n  - name: 1\n", "triggers[0].name", 2, 11},
+		{"defaults:\n  metadata:\n    labels:\n      foo: bar\n    label:\n      foo: bar\n", "defaults.metadata.label", 5, 5},
+	}
+	for _, tt := range tests {
+		var cfg Config
+		err := cfg.UnmarshalYAMLStrict([]byte(tt.yaml))
+		var d *Diagnostic
+		if assert.ErrorAs(t, err, &d, tt.yaml) {
+			assert.Equal(t, tt.path, d.Path())
+			assert.Equal(t, tt.line, d.Line)
+			assert.Equal(t, tt.column, d.Column)
+		}
+	}
+}
+
+func TestConfig_UnmarshalYAML_Diagnostic(t *testing.T) {
+	var cfg Config
+	err := cfg.UnmarshalYAML([]byte("triggers:\n  - name: pr\n  - name: 1\n"))
+	var d *Diagnostic
+	if assert.ErrorAs(t, err, &d) {
+		assert.Equal(t, "triggers[1].name", d.Path())
+		assert.Equal(t, 3, d.Line)
+		assert.Equal(t, 11, d.Column)
+	}
+	// Unknown fields are not rejected, unlike UnmarshalYAMLStrict.
+	err = cfg.UnmarshalYAML([]byte("triggers:\n  - name: main\n    filters: 'true'\n"))
+	assert.Nil(t, err)
+}
+
+func TestConfig_PipelineRuns_Diagnostic(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	ctx := pipelineresolver.WithResolver(context.TODO(), r)
+	cfg := &Config{
+		Triggers: TriggerSlice{
+			{Name: "pr", Filter: "false"},
+			{Name: "main", Filter: `body.ref == "main" && bdy.action == "opened"`},
+		},
+	}
+	_, err = cfg.PipelineRuns(ctx, &pipelineresolver.Metadata{})
+	var d *Diagnostic
+	assert.ErrorAs(t, err, &d)
+	assert.Equal(t, "triggers[1].filter", d.Path())
+	assert.Equal(t, 0, d.Line)
+
+	tests := []struct {
+		yaml   string
+		path   string
+		line   int
+		column int
+	}{
+		{
+			"triggers:\n  - name: main\n    filter: body.ref == \"main\" && bdy.action == \"opened\"\n",
+			"triggers[0].filter", 3, 35,
+		},
+		{
+			"triggers:\n  - name: main\n    filter: 'body.ref == \"main\" && bdy.action == \"opened\"'\n",
+			"triggers[0].filter", 3, 36,
+		},
+		{
+			"triggers:\n  - name: main\n    filter: >-\n      body.ref == \"main\" &&\n        bdy.action == \"opened\"\n",
+			"triggers[0].filter", 5, 9,
+		},
+		{
+			"triggers:\n  - name: main\n    filter: >-\n      body.ref == \"main\" &&\n      bdy.action == \"opened\"\n",
+			"triggers[0].filter", 5, 7,
+		},
+		{
+			"triggers:\n  - name: main\n    pipelines: []\n",
+			"", 0, 0,
+		},
+	}
+	for _, tt := range tests {
+		ok := d.Locate([]byte(tt.yaml))
+		assert.Equal(t, tt.line > 0, ok, tt.yaml)
+		if ok {
+			assert.Equal(t, tt.path, d.Path())
+			assert.Equal(t, tt.line, d.Line)
+			assert.Equal(t, tt.column, d.Column)
+		}
+	}
+}
+
+func TestConfig_PipelineRuns_PipelineDiagnostic(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	ctx := pipelineresolver.WithResolver(context.TODO(), r)
+	data := []byte("triggers:\n  - name: main\n    filter: 'true'\n    pipelines:\n      - name: build\n      - name: test\n" +
+		"        timeouts:\n          pipeline: body.repository\n")
+	var cfg Config
+	err = cfg.UnmarshalYAML(data)
+	assert.Nil(t, err)
+	_, err = cfg.PipelineRuns(ctx, &pipelineresolver.Metadata{
+		Body: map[string]interface{}{"repository": map[string]interface{}{"name": "toolbox"}},
+	})
+	var d *Diagnostic
+	if assert.ErrorAs(t, err, &d) {
+		assert.Equal(t, "triggers[0].pipelines[1].timeouts.pipeline", d.Path())
+		assert.EqualError(t, d.Err, "expected a string, got a map")
+		assert.True(t, d.Locate(data))
+		assert.Equal(t, 8, d.Line)
+		assert.Equal(t, 21, d.Column)
+	}
+}
+
+func TestTrigger_MatchPaths_Diagnostic(t *testing.T) {
+	tr := &Trigger{Paths: TriggerPaths{"services/[foo/**"}}
+	_, err := tr.MatchPaths(&pipelineresolver.Metadata{ChangedFiles: []string{"services/foo/main.go"}})
+	var d *Diagnostic
+	assert.ErrorAs(t, err, &d)
+	assert.Equal(t, "paths", d.Path())
+}
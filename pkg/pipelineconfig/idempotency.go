@@ -0,0 +1,44 @@
+package pipelineconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	v1pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+// IdempotencyKeyLabelKey labels a PipelineRun with a hash of the delivery of its event, its trigger and its pipeline,
+// so a redelivered event does not create it twice.
+const IdempotencyKeyLabelKey = "pipeline-config.tekton.dev/idempotency-key"
+
+// deliveryHeaders identify a delivery of a webhook, and are kept when it is redelivered, unlike the event ID of Tekton
+// Triggers.
+var deliveryHeaders = []string{"X-GitHub-Delivery", "Idempotency-Key"}
+
+// deliveryID returns an ID of the delivery of an event, or the event ID if it is unknown.
+func deliveryID(meta *pipelineresolver.Metadata) string {
+	for _, h := range deliveryHeaders {
+		if v := meta.Header.Get(h); v != "" {
+			return v
+		}
+	}
+	if meta.TriggerContext != nil {
+		return meta.TriggerContext.EventID
+	}
+	return ""
+}
+
+// stampIdempotencyKey labels pr with an idempotency key, if the delivery of its event is known.
+func stampIdempotencyKey(meta *pipelineresolver.Metadata, t *Trigger, pipeline string, pr *v1pipeline.PipelineRun) {
+	delivery := deliveryID(meta)
+	if delivery == "" {
+		return
+	}
+	if pr.Labels == nil {
+		pr.Labels = map[string]string{}
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s", delivery, t.Name, pipeline)))
+	pr.Labels[IdempotencyKeyLabelKey] = hex.EncodeToString(sum[:16])
+}
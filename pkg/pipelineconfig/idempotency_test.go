@@ -0,0 +1,54 @@
+package pipelineconfig
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/stretchr/testify/assert"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+)
+
+func TestConfig_PipelineRuns_IdempotencyKey(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	ctx := pipelineresolver.WithResolver(context.TODO(), r)
+	var cfg Config
+	err = cfg.UnmarshalYAMLStrict([]byte(`
+triggers:
+  - name: main
+    filter: "true"
+    pipelines:
+      - name: build
+      - name: test
+`))
+	assert.Nil(t, err)
+	keys := func(meta *pipelineresolver.Metadata) []string {
+		prs, err := cfg.PipelineRuns(ctx, meta)
+		assert.Nil(t, err)
+		var keys []string
+		for _, pr := range prs {
+			keys = append(keys, pr.Labels[IdempotencyKeyLabelKey])
+		}
+		return keys
+	}
+	delivery := func(event string) *pipelineresolver.Metadata {
+		return &pipelineresolver.Metadata{
+			Header:         http.Header{"X-Github-Delivery": []string{"delivery"}},
+			Body:           map[string]interface{}{},
+			TriggerContext: &v1beta1.TriggerContext{EventID: event},
+		}
+	}
+
+	// A redelivered event has a new event ID, but the same delivery ID.
+	first := keys(delivery("first"))
+	assert.Len(t, first[0], 32)
+	assert.NotEqual(t, first[0], first[1])
+	assert.Equal(t, first, keys(delivery("second")))
+	assert.NotEqual(t, first, keys(&pipelineresolver.Metadata{
+		Body:           map[string]interface{}{},
+		TriggerContext: &v1beta1.TriggerContext{EventID: "first"},
+	}))
+	assert.Equal(t, []string{"", ""}, keys(&pipelineresolver.Metadata{Body: map[string]interface{}{}}))
+}
@@ -0,0 +1,105 @@
+package pipelineconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const DefaultIncludeDepth = 5
+
+var (
+	ErrIncludeCycle       = errors.New("include cycle")
+	ErrIncludeDepth       = errors.New("include depth exceeded")
+	ErrIncludeUnsupported = errors.New("include scheme is not supported")
+	ErrIncludeNotAllowed  = errors.New("include is not allowed")
+)
+
+// IncludeLoader loads config referenced by an include URL, eg. `configmap://ns/name`.
+type IncludeLoader func(ctx context.Context, u *url.URL) (*Config, error)
+
+type IncludeResolver struct {
+	loaders  map[string]IncludeLoader
+	maxDepth int
+}
+
+func (r *IncludeResolver) load(ctx context.Context, include string) (*Config, error) {
+	u, err := url.Parse(include)
+	if err != nil {
+		return nil, err
+	}
+	var load IncludeLoader
+	if r != nil {
+		load = r.loaders[u.Scheme]
+	}
+	if load == nil {
+		return nil, fmt.Errorf("%w: %s", ErrIncludeUnsupported, include)
+	}
+	cfg, err := load(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load include %s: %w", include, err)
+	}
+	// Loaded config may be cached by a loader, and merging into it would modify the cached one.
+	buf, err := cfg.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	err = c.UnmarshalJSON(buf)
+	return c, err
+}
+
+func (r *IncludeResolver) resolve(ctx context.Context, cfg *Config, chain []string) (*Config, error) {
+	if len(cfg.Include) == 0 {
+		return cfg, nil
+	}
+	maxDepth := DefaultIncludeDepth
+	if r != nil {
+		maxDepth = r.maxDepth
+	}
+	if len(chain) >= maxDepth {
+		return nil, fmt.Errorf("%w: %s", ErrIncludeDepth, strings.Join(chain, " -> "))
+	}
+	res := &Config{}
+	for _, include := range cfg.Include {
+		next := append(slices.Clip(chain), include)
+		if slices.Contains(chain, include) {
+			return nil, fmt.Errorf("%w: %s", ErrIncludeCycle, strings.Join(next, " -> "))
+		}
+		c, err := r.load(ctx, include)
+		if err != nil {
+			return nil, err
+		}
+		c, err = r.resolve(ctx, c, next)
+		if err != nil {
+			return nil, err
+		}
+		err = res.Merge(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	local := *cfg
+	local.Include = nil
+	err := res.Merge(&local)
+	return res, err
+}
+
+// Resolve loads includes of cfg recursively, and merges them in order before cfg itself, so cfg overrides them.
+// A nil resolver supports no includes.
+func (r *IncludeResolver) Resolve(ctx context.Context, cfg *Config) (*Config, error) {
+	return r.resolve(ctx, cfg, nil)
+}
+
+func NewIncludeResolver(
+	maxDepth int,
+	loaders map[string]IncludeLoader,
+) *IncludeResolver {
+	return &IncludeResolver{
+		loaders:  loaders,
+		maxDepth: maxDepth,
+	}
+}
@@ -0,0 +1,110 @@
+package pipelineconfig
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelinerun"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestIncludeResolver(t *testing.T, configs map[string]string) *IncludeResolver {
+	return NewIncludeResolver(DefaultIncludeDepth, map[string]IncludeLoader{
+		"test": func(_ context.Context, u *url.URL) (*Config, error) {
+			s, ok := configs[u.Host]
+			if !ok {
+				return nil, fmt.Errorf("%s does not exist", u.Host)
+			}
+			cfg := &Config{}
+			err := cfg.UnmarshalYAML([]byte(s))
+			assert.Nil(t, err)
+			return cfg, nil
+		},
+	})
+}
+
+func TestIncludeResolver_Resolve(t *testing.T) {
+	r := newTestIncludeResolver(t, map[string]string{
+		"base": `
+defaults:
+  taskRunTemplate:
+    serviceAccountName: base
+triggers:
+  - name: pr
+    filter: "true"
+  - name: main
+    filter: "false"
+`,
+		"bundle": `
+include: ["test://base"]
+triggers:
+  - name: main
+    filter: "true"
+`,
+	})
+	cfg := &Config{
+		Include: []string{"test://bundle"},
+		Defaults: pipelinerun.PipelineRun{
+			Name: "local",
+		},
+		Triggers: TriggerSlice{
+			{Name: "pr", Filter: "body.action == 'opened'"},
+		},
+	}
+	res, err := r.Resolve(context.TODO(), cfg)
+	assert.Nil(t, err)
+	assert.Nil(t, res.Include)
+	assert.Equal(t, "base", res.Defaults.TaskRunTemplate.ServiceAccountName)
+	assert.Equal(t, "local", res.Defaults.Name)
+	assert.Len(t, res.Triggers, 2)
+	assert.Equal(t, TriggerFilter("body.action == 'opened'"), res.Triggers[0].Filter)
+	assert.Equal(t, TriggerFilter("true"), res.Triggers[1].Filter)
+}
+
+func TestIncludeResolver_Resolve_Cycle(t *testing.T) {
+	r := newTestIncludeResolver(t, map[string]string{
+		"a": `include: ["test://b"]`,
+		"b": `include: ["test://a"]`,
+	})
+	_, err := r.Resolve(context.TODO(), &Config{Include: []string{"test://a"}})
+	assert.ErrorIs(t, err, ErrIncludeCycle)
+	assert.ErrorContains(t, err, "test://a -> test://b -> test://a")
+}
+
+func TestIncludeResolver_Resolve_Depth(t *testing.T) {
+	configs := map[string]string{}
+	for i := 0; i <= DefaultIncludeDepth; i++ {
+		configs[fmt.Sprint(i)] = fmt.Sprintf(`include: ["test://%d"]`, i+1)
+	}
+	r := newTestIncludeResolver(t, configs)
+	_, err := r.Resolve(context.TODO(), &Config{Include: []string{"test://0"}})
+	assert.ErrorIs(t, err, ErrIncludeDepth)
+}
+
+func TestIncludeResolver_Resolve_Unsupported(t *testing.T) {
+	var r *IncludeResolver
+	cfg := &Config{}
+	res, err := r.Resolve(context.TODO(), cfg)
+	assert.Nil(t, err)
+	assert.Same(t, cfg, res)
+	_, err = r.Resolve(context.TODO(), &Config{Include: []string{"test://a"}})
+	assert.ErrorIs(t, err, ErrIncludeUnsupported)
+}
+
+func TestIncludeResolver_Resolve_Modes(t *testing.T) {
+	r := newTestIncludeResolver(t, map[string]string{
+		"base": `
+triggers:
+  - name: main
+    filter: body.ref == "refs/heads/main"
+`,
+	})
+	cfg := &Config{
+		Include:     []string{"test://base"},
+		Expressions: ExpressionsExplicit,
+	}
+	_, err := r.Resolve(context.TODO(), cfg)
+	assert.ErrorContains(t, err, "`explicit` differs from `implicit`")
+}
@@ -0,0 +1,162 @@
+package pipelineconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	v1pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+const (
+	// GroupLabelKey labels PipelineRuns of a trigger which has pipelines with `needs`, for a single event.
+	GroupLabelKey = "pipeline-config.tekton.dev/group"
+	// PipelineAnnotationKey is the name of the pipeline of a PipelineRun in a group.
+	PipelineAnnotationKey = "pipeline-config.tekton.dev/pipeline"
+	// NeedsAnnotationKey is a JSON list of pipelines which a PipelineRun needs. A PipelineRun with needs is created as
+	// pending, and is started once all of them succeed, or is cancelled once one of them fails.
+	NeedsAnnotationKey = "pipeline-config.tekton.dev/needs"
+	// NeedsSucceededAnnotationKey marks a pending PipelineRun whose needs succeeded, but which waits for a free slot of
+	// its concurrency group.
+	NeedsSucceededAnnotationKey = "pipeline-config.tekton.dev/needs-succeeded"
+)
+
+// validateNeeds checks that pipelines of a trigger need other existing pipelines, and that needs have no cycles.
+func (t *Trigger) validateNeeds() error {
+	if len(t.Defaults.Needs) > 0 {
+		return FieldError("defaults", FieldError("needs", errors.New("is not supported in defaults")))
+	}
+	index := map[string]int{}
+	for j, p := range t.Pipelines {
+		index[p.Name] = j
+	}
+	for j, p := range t.Pipelines {
+		for _, n := range p.Needs {
+			var err error
+			if _, ok := index[n]; !ok {
+				err = fmt.Errorf("pipeline %s does not exist", n)
+			} else if n == p.Name {
+				err = errors.New("pipeline can not need itself")
+			}
+			if err != nil {
+				return ItemError("pipelines", j, p.Name, FieldError("needs", err))
+			}
+		}
+	}
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(t.Pipelines))
+	var path []string
+	var visit func(j int) error
+	visit = func(j int) error {
+		p := t.Pipelines[j]
+		switch state[j] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := append(path[indexOf(path, p.Name):], p.Name)
+			return ItemError("pipelines", j, p.Name, FieldError("needs",
+				fmt.Errorf("cycle of pipelines %s", strings.Join(cycle, " -> "))))
+		}
+		state[j] = visiting
+		path = append(path, p.Name)
+		for _, n := range p.Needs {
+			if err := visit(index[n]); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[j] = visited
+		return nil
+	}
+	for j := range t.Pipelines {
+		if err := visit(j); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func indexOf(s []string, v string) int {
+	for i, e := range s {
+		if e == v {
+			return i
+		}
+	}
+	return 0
+}
+
+func (t *Trigger) hasNeeds() bool {
+	for _, p := range t.Pipelines {
+		if len(p.Needs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks fields of the config which can be checked without an event, eg. cycles of `needs`.
+func (c *Config) Validate() error {
+	if len(c.Defaults.Needs) > 0 {
+		return FieldError("defaults", FieldError("needs", errors.New("is not supported in defaults")))
+	}
+	for i, t := range c.Triggers {
+		if err := t.validateNeeds(); err != nil {
+			return ItemError("triggers", i, t.Name, err)
+		}
+		if t.Concurrency != nil {
+			if err := t.Concurrency.validate(); err != nil {
+				return ItemError("triggers", i, t.Name, FieldError("concurrency", err))
+			}
+		}
+	}
+	return nil
+}
+
+// groupID identifies PipelineRuns of trigger i for an event ID of Tekton Triggers, or for a single call if it is
+// unknown. A redelivered event has a new event ID, so its PipelineRuns do not need ones of a previous delivery, even
+// though they have the same delivery ID.
+func groupID(meta *pipelineresolver.Metadata, i int, t *Trigger) string {
+	var event string
+	if meta.TriggerContext != nil {
+		event = meta.TriggerContext.EventID
+	}
+	if event == "" {
+		event = string(uuid.NewUUID())
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%d/%s", deliveryID(meta), event, i, t.Name)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// groupPipelineRuns labels PipelineRuns of a trigger with group, and creates ones with needs as pending.
+func groupPipelineRuns(group string, t *Trigger, prs []*v1pipeline.PipelineRun) error {
+	for j, p := range t.Pipelines {
+		pr := prs[j]
+		if pr.Labels == nil {
+			pr.Labels = map[string]string{}
+		}
+		if pr.Annotations == nil {
+			pr.Annotations = map[string]string{}
+		}
+		pr.Labels[GroupLabelKey] = group
+		pr.Annotations[PipelineAnnotationKey] = p.Name
+		if len(p.Needs) == 0 {
+			continue
+		}
+		needs, err := json.Marshal(p.Needs)
+		if err != nil {
+			return err
+		}
+		pr.Annotations[NeedsAnnotationKey] = string(needs)
+		pr.Spec.Status = v1pipeline.PipelineRunSpecStatusPending
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package pipelineconfig
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/stretchr/testify/assert"
+	v1pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+)
+
+func TestConfig_Validate_Needs(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		path string
+		err  string
+	}{
+		{
+			name: "unknown",
+			yaml: `
+triggers:
+  - name: main
+    pipelines:
+      - name: build
+      - name: deploy
+        needs: [test]
+`,
+			path: "triggers[0].pipelines[1].needs",
+			err:  "pipeline test does not exist",
+		},
+		{
+			name: "self",
+			yaml: `
+triggers:
+  - name: main
+    pipelines:
+      - name: build
+        needs: [build]
+`,
+			path: "triggers[0].pipelines[0].needs",
+			err:  "pipeline can not need itself",
+		},
+		{
+			name: "cycle",
+			yaml: `
+triggers:
+  - name: main
+    pipelines:
+      - name: build
+        needs: [deploy]
+      - name: test
+        needs: [build]
+      - name: deploy
+        needs: [test]
+`,
+			path: "triggers[0].pipelines[0].needs",
+			err:  "cycle of pipelines build -> deploy -> test -> build",
+		},
+		{
+			name: "defaults",
+			yaml: `
+triggers:
+  - name: main
+    defaults:
+      needs: [build]
+    pipelines:
+      - name: build
+`,
+			path: "triggers[0].defaults.needs",
+			err:  "is not supported in defaults",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			err := cfg.UnmarshalYAMLStrict([]byte(tt.yaml))
+			assert.Nil(t, err)
+			err = cfg.Validate()
+			var d *Diagnostic
+			assert.ErrorAs(t, err, &d)
+			assert.Equal(t, tt.path, d.Path())
+			assert.EqualError(t, d.Err, tt.err)
+		})
+	}
+}
+
+func TestConfig_PipelineRuns_Needs(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	ctx := pipelineresolver.WithResolver(context.TODO(), r)
+	meta := &pipelineresolver.Metadata{
+		Body:           map[string]interface{}{},
+		TriggerContext: &v1beta1.TriggerContext{EventID: "event"},
+	}
+	var cfg Config
+	err = cfg.UnmarshalYAMLStrict([]byte(`
+triggers:
+  - name: main
+    filter: "true"
+    pipelines:
+      - name: build
+      - name: test
+      - name: deploy
+        needs: [build, test]
+      - name: notify
+        needs: [deploy]
+`))
+	assert.Nil(t, err)
+	prs, err := cfg.PipelineRuns(ctx, meta)
+	assert.Nil(t, err)
+	assert.Len(t, prs, 4)
+	build, deploy, notify := prs[0], prs[2], prs[3]
+	group := build.Labels[GroupLabelKey]
+	assert.Len(t, group, 16)
+	for _, pr := range prs {
+		assert.Equal(t, group, pr.Labels[GroupLabelKey])
+	}
+	assert.Equal(t, "build", build.Annotations[PipelineAnnotationKey])
+	assert.Empty(t, build.Spec.Status)
+	assert.NotContains(t, build.Annotations, NeedsAnnotationKey)
+	assert.Equal(t, v1pipeline.PipelineRunSpecStatus(v1pipeline.PipelineRunSpecStatusPending), deploy.Spec.Status)
+	assert.Equal(t, `["build","test"]`, deploy.Annotations[NeedsAnnotationKey])
+	assert.Equal(t, `["deploy"]`, notify.Annotations[NeedsAnnotationKey])
+
+	// Groups of the same event are the same, so PipelineRuns of a retried event are in the same group.
+	prs, err = cfg.PipelineRuns(ctx, meta)
+	assert.Nil(t, err)
+	assert.Equal(t, group, prs[0].Labels[GroupLabelKey])
+
+	// A redelivered event has the same delivery ID, but a new event ID, so it does not mix with a previous delivery.
+	delivery := func(event string) *pipelineresolver.Metadata {
+		return &pipelineresolver.Metadata{
+			Header:         http.Header{"X-Github-Delivery": []string{"delivery"}},
+			Body:           map[string]interface{}{},
+			TriggerContext: &v1beta1.TriggerContext{EventID: event},
+		}
+	}
+	prs, err = cfg.PipelineRuns(ctx, delivery("first"))
+	assert.Nil(t, err)
+	redelivered, err := cfg.PipelineRuns(ctx, delivery("second"))
+	assert.Nil(t, err)
+	assert.NotEqual(t, prs[0].Labels[GroupLabelKey], redelivered[0].Labels[GroupLabelKey])
+	assert.Equal(t, prs[0].Labels[IdempotencyKeyLabelKey], redelivered[0].Labels[IdempotencyKeyLabelKey])
+}
@@ -0,0 +1,30 @@
+package pipelineconfig
+
+import (
+	"reflect"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/jsonschema"
+	v1pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// JSONSchema returns a schema of Config, so editors and pre-commit hooks can validate `.tekton.yaml` files.
+func JSONSchema() *jsonschema.Schema {
+	r := jsonschema.NewReflector()
+	r.Override(reflect.TypeOf(metav1.Time{}), &jsonschema.Schema{Type: "string", Format: "date-time"})
+	r.Override(reflect.TypeOf(metav1.Duration{}), &jsonschema.Schema{Type: "string"})
+	r.Override(reflect.TypeOf(resource.Quantity{}), &jsonschema.Schema{Type: []string{"string", "number"}})
+	r.Override(reflect.TypeOf(intstr.IntOrString{}), &jsonschema.Schema{Type: []string{"string", "integer"}})
+	r.Override(reflect.TypeOf(v1pipeline.ParamValue{}), &jsonschema.Schema{
+		OneOf: []*jsonschema.Schema{
+			{Type: "string"},
+			{Type: "array", Items: &jsonschema.Schema{Type: "string"}},
+			{Type: "object", AdditionalProperties: &jsonschema.Schema{Type: "string"}},
+		},
+	})
+	s := r.Reflect(&Config{})
+	s.Title = ConfigKey
+	return s
+}
@@ -0,0 +1,30 @@
+package pipelineconfig
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONSchema(t *testing.T) {
+	s := JSONSchema()
+	assert.Contains(t, s.Properties, "include")
+	assert.Contains(t, s.Properties, "defaults")
+	assert.Equal(t, false, s.AdditionalProperties)
+	triggers := s.Properties["triggers"]
+	assert.Equal(t, "array", triggers.Type)
+	trigger := s.Defs["github.com.ElementalCognition.tekton-toolbox.pkg.pipelineconfig.Trigger"]
+	assert.NotNil(t, trigger)
+	assert.Contains(t, trigger.Properties, "paths")
+	pipeline := s.Defs["github.com.ElementalCognition.tekton-toolbox.pkg.pipelinerun.PipelineRun"]
+	assert.NotNil(t, pipeline)
+	for _, name := range []string{"name", "metadata", "params", "pipelineRef", "timeouts", "workspaces"} {
+		assert.Contains(t, pipeline.Properties, name)
+	}
+	param := s.Defs["github.com.ElementalCognition.tekton-toolbox.pkg.pipelinerun.Param"]
+	assert.NotNil(t, param)
+	assert.Len(t, param.Properties["value"].OneOf, 3)
+	_, err := json.Marshal(s)
+	assert.Nil(t, err)
+}
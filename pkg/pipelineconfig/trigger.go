@@ -1,12 +1,32 @@
 package pipelineconfig
 
-import "github.com/ElementalCognition/tekton-toolbox/pkg/pipelinerun"
+import (
+	"context"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelinerun"
+)
 
 type Trigger struct {
-	Name      string                    `json:"name,omitempty"`
-	Filter    TriggerFilter             `json:"filter,omitempty"`
-	Defaults  pipelinerun.PipelineRun   `json:"defaults,omitempty"`
-	Pipelines pipelinerun.PipelineSlice `json:"pipelines,omitempty"`
+	Name        string                    `json:"name,omitempty"`
+	Filter      TriggerFilter             `json:"filter,omitempty"`
+	Paths       TriggerPaths              `json:"paths,omitempty"`
+	PathsIgnore TriggerPaths              `json:"pathsIgnore,omitempty"`
+	Concurrency *Concurrency              `json:"concurrency,omitempty"`
+	Defaults    pipelinerun.PipelineRun   `json:"defaults,omitempty"`
+	Pipelines   pipelinerun.PipelineSlice `json:"pipelines,omitempty"`
 }
 
 type TriggerSlice []Trigger
+
+// Match reports whether both filter and paths of the trigger match.
+func (t *Trigger) Match(ctx context.Context, meta *pipelineresolver.Metadata) (bool, error) {
+	ok, err := t.Filter.Match(ctx, meta)
+	if err != nil {
+		return false, FieldError("filter", err)
+	}
+	if !ok {
+		return false, nil
+	}
+	return t.MatchPaths(meta)
+}
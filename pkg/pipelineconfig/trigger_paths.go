@@ -0,0 +1,63 @@
+package pipelineconfig
+
+import (
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pathglob"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+)
+
+type TriggerPaths []string
+
+// MatchAny reports whether at least one of changed files matches the globs.
+func (p TriggerPaths) MatchAny(files []string) (bool, error) {
+	for _, f := range files {
+		ok, err := pathglob.MatchAny(p, f)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// MatchAll reports whether every changed file matches the globs.
+func (p TriggerPaths) MatchAll(files []string) (bool, error) {
+	for _, f := range files {
+		ok, err := pathglob.MatchAny(p, f)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// MatchPaths reports whether changed files pass `paths` and `pathsIgnore` of the trigger.
+// Changed files are unknown for some events, eg. a tag push, and then path filters are not applied.
+func (t *Trigger) MatchPaths(meta *pipelineresolver.Metadata) (bool, error) {
+	if meta.ChangedFiles == nil {
+		return true, nil
+	}
+	if len(t.Paths) > 0 {
+		ok, err := t.Paths.MatchAny(meta.ChangedFiles)
+		if err != nil {
+			return false, FieldError("paths", err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	if len(t.PathsIgnore) > 0 && len(meta.ChangedFiles) > 0 {
+		ok, err := t.PathsIgnore.MatchAll(meta.ChangedFiles)
+		if err != nil {
+			return false, FieldError("pathsIgnore", err)
+		}
+		if ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,93 @@
+package pipelineconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelinerun"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrigger_MatchPaths(t *testing.T) {
+	tr := &Trigger{
+		Paths:       TriggerPaths{"services/foo/**"},
+		PathsIgnore: TriggerPaths{"**/*.md"},
+	}
+	tests := []struct {
+		files []string
+		match bool
+	}{
+		{nil, true},
+		{[]string{}, false},
+		{[]string{"services/foo/main.go"}, true},
+		{[]string{"services/bar/main.go"}, false},
+		{[]string{"services/foo/README.md"}, false},
+		{[]string{"services/foo/README.md", "services/foo/main.go"}, true},
+	}
+	for _, tt := range tests {
+		m, err := tr.MatchPaths(&pipelineresolver.Metadata{ChangedFiles: tt.files})
+		assert.Nil(t, err)
+		assert.Equal(t, tt.match, m, "%v", tt.files)
+	}
+}
+
+func TestConfig_PipelineRuns_Paths(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	ctx := pipelineresolver.WithResolver(context.TODO(), r)
+	cfg := &Config{
+		Triggers: TriggerSlice{
+			{
+				Name:      "foo",
+				Filter:    "true",
+				Paths:     TriggerPaths{"services/foo/**"},
+				Pipelines: pipelinerun.PipelineSlice{{Name: "foo"}},
+			},
+			{
+				Name:      "bar",
+				Filter:    `changedFiles.exists(f, f.startsWith("services/bar/"))`,
+				Pipelines: pipelinerun.PipelineSlice{{Name: "bar"}},
+			},
+		},
+	}
+	assert.True(t, cfg.NeedsChangedFiles(ctx))
+	prs, err := cfg.PipelineRuns(ctx, &pipelineresolver.Metadata{
+		ChangedFiles: []string{"services/bar/main.go"},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, prs, 1)
+	assert.Equal(t, "bar-run-", prs[0].GenerateName)
+}
+
+func TestConfig_NeedsChangedFiles(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	ctx := pipelineresolver.WithResolver(context.TODO(), r)
+	for _, tt := range []struct {
+		cfg   string
+		needs bool
+	}{
+		{cfg: "triggers: [{name: main, filter: 'true'}]", needs: false},
+		{cfg: "triggers: [{name: main, pathsIgnore: ['docs/**']}]", needs: true},
+		{cfg: "triggers: [{name: main, filter: 'changedFiles.size() > 0'}]", needs: true},
+		{cfg: "defaults: {params: [{name: files, value: 'changedFiles.join(\",\")'}]}", needs: true},
+		{
+			cfg:   "triggers: [{name: main, pipelines: [{name: foo, params: [{name: files, value: '${{ changedFiles }}'}]}]}]",
+			needs: false,
+		},
+		{
+			cfg:   "expressions: explicit\ntriggers: [{name: main, pipelines: [{name: foo, params: [{name: files, value: '${{ changedFiles }}'}]}]}]",
+			needs: true,
+		},
+		{cfg: "expressions: explicit\ndefaults: {params: [{name: files, value: 'changedFiles-${{ body.ref }}'}]}", needs: false},
+		{cfg: "triggers: [{name: main, filter: 'body.changedFiles.size() > 0'}]", needs: false},
+		{cfg: "defaults: {params: [{name: docs, value: 'see changedFiles below'}]}", needs: false},
+		{cfg: "resolver: template\ndefaults: {params: [{name: files, value: '{{ join \",\" .changedFiles }}'}]}", needs: true},
+		{cfg: "resolver: template\ndefaults: {params: [{name: files, value: '{{ .body.changedFiles }}'}]}", needs: false},
+	} {
+		var cfg Config
+		assert.Nil(t, cfg.UnmarshalYAMLStrict([]byte(tt.cfg)), tt.cfg)
+		assert.Equal(t, tt.needs, cfg.NeedsChangedFiles(ctx), tt.cfg)
+	}
+}
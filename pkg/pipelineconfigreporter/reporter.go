@@ -0,0 +1,13 @@
+package pipelineconfigreporter
+
+import (
+	"context"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+)
+
+// Reporter reports config errors back to the commit which triggered them, since interceptor failures are only
+// visible in logs.
+type Reporter interface {
+	Report(ctx context.Context, meta *pipelineresolver.Metadata, err error) error
+}
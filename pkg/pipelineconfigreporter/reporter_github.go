@@ -0,0 +1,100 @@
+package pipelineconfigreporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/google/go-github/v43/github"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+const CheckRunName = "pipeline-config"
+
+type githubReporter struct {
+	githubClient *github.Client
+}
+
+var _ Reporter = (*githubReporter)(nil)
+
+func stringAt(body map[string]interface{}, keys ...string) string {
+	var v interface{} = body
+	for _, k := range keys {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		v = m[k]
+	}
+	s, _ := v.(string)
+	return s
+}
+
+// headCommit returns the repository and the head commit of pull request and push events.
+func headCommit(body map[string]interface{}) (owner, repo, sha string) {
+	owner = stringAt(body, "repository", "owner", "login")
+	repo = stringAt(body, "repository", "name")
+	sha = stringAt(body, "pull_request", "head", "sha")
+	if sha == "" {
+		sha = stringAt(body, "after")
+	}
+	return owner, repo, sha
+}
+
+func checkRunOutput(err error) *github.CheckRunOutput {
+	title := "Config is invalid"
+	var d *pipelineconfig.Diagnostic
+	if errors.As(err, &d) {
+		title = fmt.Sprintf("Config field %s is invalid", d.Path())
+	}
+	return &github.CheckRunOutput{
+		Title:   github.String(title),
+		Summary: github.String(fmt.Sprintf("No pipelines were triggered:\n```\n%v\n```", err)),
+	}
+}
+
+func (r *githubReporter) Report(ctx context.Context, meta *pipelineresolver.Metadata, err error) error {
+	logger := logging.FromContext(ctx)
+	owner, repo, sha := headCommit(meta.Body)
+	if owner == "" || repo == "" || sha == "" {
+		logger.Debugw("Reporter did not find head commit of event; skipping")
+		return nil
+	}
+	logger = logger.With(
+		zap.String("owner", owner),
+		zap.String("repo", repo),
+		zap.String("sha", sha),
+	)
+	_, res, err := r.githubClient.Checks.CreateCheckRun(ctx, owner, repo, github.CreateCheckRunOptions{
+		Name:        CheckRunName,
+		HeadSHA:     sha,
+		Status:      github.String("completed"),
+		Conclusion:  github.String("failure"),
+		CompletedAt: &github.Timestamp{Time: time.Now()},
+		Output:      checkRunOutput(err),
+	})
+	if err != nil {
+		keyAndVals := []any{zap.Error(err)}
+		if res != nil {
+			keyAndVals = append(keyAndVals, zap.String("responseStatus", res.Status))
+		}
+		logger.Errorw("Reporter failed to create check run", keyAndVals...)
+		return err
+	}
+	logger.Infow("Reporter created check run", zap.String("responseStatus", res.Status))
+	return nil
+}
+
+// NewGithubReporter reports config errors as a failing `pipeline-config` check run on the head commit of GitHub
+// pull request and push events.
+func NewGithubReporter(
+	githubClient *github.Client,
+) Reporter {
+	return &githubReporter{
+		githubClient: githubClient,
+	}
+}
@@ -0,0 +1,75 @@
+package pipelineconfigreporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/google/go-github/v43/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestReporter(t *testing.T) (Reporter, *[]github.CreateCheckRunOptions) {
+	var checkRuns []github.CreateCheckRunOptions
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/{owner}/{repo}/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "ec", r.PathValue("owner"))
+		assert.Equal(t, "tekton-toolbox", r.PathValue("repo"))
+		var opts github.CreateCheckRunOptions
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&opts))
+		checkRuns = append(checkRuns, opts)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(&github.CheckRun{ID: github.Int64(1)})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	githubClient := github.NewClient(nil)
+	baseURL, err := url.Parse(srv.URL + "/")
+	assert.Nil(t, err)
+	githubClient.BaseURL = baseURL
+	return NewGithubReporter(githubClient), &checkRuns
+}
+
+func TestGithubReporter_Report(t *testing.T) {
+	r, checkRuns := newTestReporter(t)
+	repository := map[string]interface{}{
+		"name":  "tekton-toolbox",
+		"owner": map[string]interface{}{"login": "ec"},
+	}
+	err := pipelineconfig.ItemError("triggers", 2, "main", pipelineconfig.FieldError("filter", errors.New("undeclared reference to 'bdy'")))
+	for _, body := range []map[string]interface{}{
+		{
+			"repository":   repository,
+			"pull_request": map[string]interface{}{"head": map[string]interface{}{"sha": "abc"}},
+		},
+		{
+			"repository": repository,
+			"after":      "abc",
+		},
+	} {
+		assert.Nil(t, r.Report(context.TODO(), &pipelineresolver.Metadata{Body: body}, err))
+	}
+	assert.Len(t, *checkRuns, 2)
+	for _, cr := range *checkRuns {
+		assert.Equal(t, CheckRunName, cr.Name)
+		assert.Equal(t, "abc", cr.HeadSHA)
+		assert.Equal(t, "failure", cr.GetConclusion())
+		assert.Equal(t, "Config field triggers[2].filter is invalid", cr.GetOutput().GetTitle())
+		assert.Contains(t, cr.GetOutput().GetSummary(), "undeclared reference to 'bdy'")
+	}
+}
+
+func TestGithubReporter_Report_UnsupportedEvent(t *testing.T) {
+	r, checkRuns := newTestReporter(t)
+	err := r.Report(context.TODO(), &pipelineresolver.Metadata{
+		Body: map[string]interface{}{"object_kind": "push"},
+	}, errors.New("config is malformed"))
+	assert.Nil(t, err)
+	assert.Empty(t, *checkRuns)
+}
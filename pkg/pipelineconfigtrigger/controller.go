@@ -0,0 +1,91 @@
+package pipelineconfigtrigger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"github.com/tektoncd/pipeline/pkg/client/informers/externalversions"
+	v1informers "github.com/tektoncd/pipeline/pkg/client/informers/externalversions/pipeline/v1"
+	v1listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	"knative.dev/pkg/logging"
+)
+
+// resyncPeriod is how often PipelineRuns are checked again, in case an event was missed, eg. while the controller was
+// down.
+const resyncPeriod = 10 * time.Minute
+
+// Controller reconciles PipelineRuns which the interceptor created.
+type Controller interface {
+	Run(ctx context.Context) error
+}
+
+type controller struct {
+	tektonClient versioned.Interface
+	factory      externalversions.SharedInformerFactory
+	informer     v1informers.PipelineRunInformer
+	lister       v1listers.PipelineRunLister
+	queue        workqueue.RateLimitingInterface
+	syncHandler  func(ctx context.Context, key string) error
+}
+
+var _ Controller = (*controller)(nil)
+
+func (c *controller) processNextItem(ctx context.Context) bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+	err := c.syncHandler(ctx, key.(string))
+	if err != nil {
+		logging.FromContext(ctx).Errorw("Controller failed to sync pipeline runs",
+			zap.String("key", key.(string)),
+			zap.Error(err),
+		)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) Run(ctx context.Context) error {
+	defer c.queue.ShutDown()
+	c.factory.Start(ctx.Done())
+	for t, ok := range c.factory.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return fmt.Errorf("failed to sync informer of %v", t)
+		}
+	}
+	go wait.UntilWithContext(ctx, func(ctx context.Context) {
+		for c.processNextItem(ctx) {
+		}
+	}, time.Second)
+	<-ctx.Done()
+	return nil
+}
+
+// newController watches PipelineRuns with labelKey in every namespace.
+func newController(tektonClient versioned.Interface, labelKey string) *controller {
+	factory := externalversions.NewSharedInformerFactoryWithOptions(tektonClient, resyncPeriod,
+		externalversions.WithNamespace(corev1.NamespaceAll),
+		externalversions.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.LabelSelector = labelKey
+		}),
+	)
+	informer := factory.Tekton().V1().PipelineRuns()
+	return &controller{
+		tektonClient: tektonClient,
+		factory:      factory,
+		informer:     informer,
+		lister:       informer.Lister(),
+		queue:        workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+}
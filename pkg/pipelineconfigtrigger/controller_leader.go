@@ -0,0 +1,91 @@
+package pipelineconfigtrigger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
+type leaderElectedController struct {
+	controller Controller
+	lock       *resourcelock.LeaseLock
+}
+
+var _ Controller = (*leaderElectedController)(nil)
+
+// Run runs the controller while it holds the lease, and fails once the lease is lost, so the replica restarts instead
+// of running the controller along with a new leader.
+func (c *leaderElectedController) Run(ctx context.Context) error {
+	logger := logging.FromContext(ctx).With(
+		zap.String("namespace", c.lock.LeaseMeta.Namespace),
+		zap.String("lease", c.lock.LeaseMeta.Name),
+		zap.String("identity", c.lock.Identity()),
+	)
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            c.lock,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				logger.Infow("Controller started leading")
+				close(started)
+				done <- c.controller.Run(ctx)
+			},
+			OnStoppedLeading: func() {
+				logger.Infow("Controller stopped leading")
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	le.Run(ctx)
+	select {
+	case <-started:
+		err = <-done
+	default:
+	}
+	if err == nil && ctx.Err() == nil {
+		err = fmt.Errorf("lost lease %s/%s", c.lock.LeaseMeta.Namespace, c.lock.LeaseMeta.Name)
+	}
+	return err
+}
+
+// NewLeaderElectedController runs controller in a single replica of the interceptor, which holds a Lease of name in
+// namespace, eg. so two replicas of the queue controller do not start more PipelineRuns than max concurrent.
+func NewLeaderElectedController(
+	controller Controller,
+	kubeClient kubernetes.Interface,
+	namespace, name, identity string,
+) Controller {
+	return &leaderElectedController{
+		controller: controller,
+		lock: &resourcelock.LeaseLock{
+			LeaseMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      name,
+			},
+			Client: kubeClient.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{
+				Identity: identity,
+			},
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package pipelineconfigtrigger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeController struct {
+	started chan struct{}
+}
+
+func (c *fakeController) Run(ctx context.Context) error {
+	close(c.started)
+	<-ctx.Done()
+	return nil
+}
+
+func TestLeaderElectedController_Run(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	fc := &fakeController{started: make(chan struct{})}
+	c := NewLeaderElectedController(fc, kubeClient, "tekton", "queue-controller", "replica-a")
+	ctx, cancel := context.WithCancel(context.TODO())
+	errs := make(chan error)
+	go func() {
+		errs <- c.Run(ctx)
+	}()
+	<-fc.started
+	lease, err := kubeClient.CoordinationV1().Leases("tekton").Get(context.TODO(), "queue-controller", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "replica-a", *lease.Spec.HolderIdentity)
+	cancel()
+	assert.Nil(t, <-errs)
+}
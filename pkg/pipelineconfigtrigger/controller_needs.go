@@ -0,0 +1,134 @@
+package pipelineconfigtrigger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/logging"
+)
+
+var needsSucceededPatch = []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:"true"}}}`,
+	pipelineconfig.NeedsSucceededAnnotationKey))
+
+type needsController struct {
+	*controller
+}
+
+func succeeded(pr *v1.PipelineRun) bool {
+	return pr.Status.GetCondition(apis.ConditionSucceeded).IsTrue()
+}
+
+func failed(pr *v1.PipelineRun) bool {
+	return pr.Status.GetCondition(apis.ConditionSucceeded).IsFalse()
+}
+
+// waiting reports whether a PipelineRun is pending until its needs succeed.
+func waiting(pr *v1.PipelineRun) bool {
+	return pr.IsPending() &&
+		pr.Annotations[pipelineconfig.NeedsAnnotationKey] != "" &&
+		pr.Annotations[pipelineconfig.NeedsSucceededAnnotationKey] == ""
+}
+
+func (c *needsController) enqueue(obj interface{}) {
+	pr, ok := obj.(*v1.PipelineRun)
+	if !ok || !waiting(pr) {
+		return
+	}
+	c.queue.Add(pr.Labels[pipelineconfig.GroupLabelKey])
+}
+
+// enqueueUpdate queues a group once a PipelineRun of it is done, since PipelineRuns which need it may start.
+func (c *needsController) enqueueUpdate(oldObj, obj interface{}) {
+	old, ok := oldObj.(*v1.PipelineRun)
+	pr, ok2 := obj.(*v1.PipelineRun)
+	if ok && ok2 && !old.IsDone() && pr.IsDone() {
+		c.queue.Add(pr.Labels[pipelineconfig.GroupLabelKey])
+		return
+	}
+	c.enqueue(obj)
+}
+
+func (c *needsController) patch(ctx context.Context, pr *v1.PipelineRun, patch []byte) error {
+	_, err := c.tektonClient.TektonV1().
+		PipelineRuns(pr.Namespace).
+		Patch(ctx, pr.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// reconcileNeeds starts a waiting PipelineRun once all its needs succeed, or cancels it once one of them fails, so
+// PipelineRuns which need it are cancelled too. PipelineRuns are only patched, so nothing is created again once
+// PipelineRuns of a group are deleted.
+func (c *needsController) reconcileNeeds(ctx context.Context, pr *v1.PipelineRun, runs map[string][]*v1.PipelineRun) error {
+	var needs []string
+	err := json.Unmarshal([]byte(pr.Annotations[pipelineconfig.NeedsAnnotationKey]), &needs)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal needs of %s: %w", pr.Name, err)
+	}
+	logger := logging.FromContext(ctx).With(
+		zap.String("namespace", pr.Namespace),
+		zap.String("name", pr.Name),
+		zap.String("group", pr.Labels[pipelineconfig.GroupLabelKey]),
+	)
+	for _, n := range needs {
+		var ok bool
+		for _, r := range runs[n] {
+			if failed(r) {
+				logger.Infow("Controller cancelled pipeline run, since its need failed", zap.String("need", n))
+				return c.patch(ctx, pr, cancelPatch)
+			}
+			ok = ok || succeeded(r)
+		}
+		if !ok {
+			return nil
+		}
+	}
+	if limited(pr) {
+		// The queue controller starts it once its concurrency group has a free slot.
+		logger.Infow("Controller queued pipeline run, since its needs succeeded")
+		return c.patch(ctx, pr, needsSucceededPatch)
+	}
+	logger.Infow("Controller started pipeline run, since its needs succeeded")
+	return c.patch(ctx, pr, startPatch)
+}
+
+func (c *needsController) sync(ctx context.Context, group string) error {
+	prs, err := c.lister.List(labels.SelectorFromSet(labels.Set{pipelineconfig.GroupLabelKey: group}))
+	if err != nil {
+		return err
+	}
+	runs := map[string][]*v1.PipelineRun{}
+	for _, pr := range prs {
+		pipeline := pr.Annotations[pipelineconfig.PipelineAnnotationKey]
+		runs[pipeline] = append(runs[pipeline], pr)
+	}
+	for _, pr := range prs {
+		if !waiting(pr) {
+			continue
+		}
+		if err := c.reconcileNeeds(ctx, pr, runs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewNeedsController starts pending PipelineRuns of a group which need other ones, once all of them succeed.
+func NewNeedsController(tektonClient versioned.Interface) Controller {
+	c := &needsController{newController(tektonClient, pipelineconfig.GroupLabelKey)}
+	c.syncHandler = c.sync
+	c.informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueue,
+		UpdateFunc: c.enqueueUpdate,
+	})
+	return c
+}
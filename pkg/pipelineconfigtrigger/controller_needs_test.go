@@ -0,0 +1,100 @@
+package pipelineconfigtrigger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/stretchr/testify/assert"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+const testGroup = "0123456789abcdef"
+
+func groupRun(name string, status corev1.ConditionStatus) *v1.PipelineRun {
+	pr := &v1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "tekton",
+			Name:        name,
+			Labels:      map[string]string{pipelineconfig.GroupLabelKey: testGroup},
+			Annotations: map[string]string{pipelineconfig.PipelineAnnotationKey: name},
+		},
+	}
+	if status != "" {
+		pr.Status.Status = duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: status}}}
+	}
+	return pr
+}
+
+func waitingRun(name string, needs string) *v1.PipelineRun {
+	pr := groupRun(name, "")
+	pr.Annotations[pipelineconfig.NeedsAnnotationKey] = needs
+	pr.Spec.Status = v1.PipelineRunSpecStatusPending
+	return pr
+}
+
+func syncNeedsController(t *testing.T, prs ...*v1.PipelineRun) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	for _, pr := range prs {
+		_, err := client.TektonV1().PipelineRuns(pr.Namespace).Create(context.TODO(), pr, metav1.CreateOptions{})
+		assert.Nil(t, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := NewNeedsController(client).(*needsController)
+	c.factory.Start(ctx.Done())
+	c.factory.WaitForCacheSync(ctx.Done())
+	assert.Nil(t, c.sync(ctx, testGroup))
+	return client
+}
+
+func TestNeedsController_Sync(t *testing.T) {
+	tests := []struct {
+		name       string
+		test       corev1.ConditionStatus
+		limited    bool
+		status     v1.PipelineRunSpecStatus
+		annotation string
+	}{
+		{name: "succeeded", test: corev1.ConditionTrue, status: ""},
+		{name: "running", test: corev1.ConditionUnknown, status: v1.PipelineRunSpecStatusPending},
+		{name: "failed", test: corev1.ConditionFalse, status: v1.PipelineRunSpecStatusCancelled},
+		{
+			name:       "limited",
+			test:       corev1.ConditionTrue,
+			limited:    true,
+			status:     v1.PipelineRunSpecStatusPending,
+			annotation: "true",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deploy := waitingRun("deploy", `["build","test"]`)
+			if tt.limited {
+				deploy.Annotations[pipelineconfig.MaxConcurrentAnnotationKey] = "1"
+			}
+			client := syncNeedsController(t,
+				groupRun("build", corev1.ConditionTrue),
+				groupRun("test", tt.test),
+				deploy,
+			)
+			pr, err := client.TektonV1().PipelineRuns("tekton").Get(context.TODO(), "deploy", metav1.GetOptions{})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.status, pr.Spec.Status)
+			assert.Equal(t, tt.annotation, pr.Annotations[pipelineconfig.NeedsSucceededAnnotationKey])
+		})
+	}
+}
+
+func TestNeedsController_Sync_Deleted(t *testing.T) {
+	// Nothing is created again once PipelineRuns of a group are deleted, eg. by a pruner.
+	client := syncNeedsController(t, groupRun("build", corev1.ConditionTrue))
+	prs, err := client.TektonV1().PipelineRuns("tekton").List(context.TODO(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, prs.Items, 1)
+}
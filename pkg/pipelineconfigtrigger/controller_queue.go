@@ -0,0 +1,144 @@
+package pipelineconfigtrigger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/logging"
+)
+
+var startPatch = []byte(`{"spec":{"status":null}}`)
+
+type queueController struct {
+	*controller
+}
+
+// concurrencyKey is a key of a concurrency group of a PipelineRun, which is queued instead of the PipelineRun itself.
+func concurrencyKey(pr *v1.PipelineRun) string {
+	return pr.Namespace + "/" + pr.Labels[pipelineconfig.ConcurrencyLabelKey]
+}
+
+func limited(pr *v1.PipelineRun) bool {
+	return pr.Annotations[pipelineconfig.MaxConcurrentAnnotationKey] != ""
+}
+
+func (c *queueController) enqueue(obj interface{}) {
+	pr, ok := obj.(*v1.PipelineRun)
+	if !ok || !limited(pr) || !pr.IsPending() || waiting(pr) {
+		return
+	}
+	c.queue.Add(concurrencyKey(pr))
+}
+
+// enqueueUpdate queues a concurrency group once a PipelineRun of it is done, since it frees a slot.
+func (c *queueController) enqueueUpdate(oldObj, obj interface{}) {
+	old, ok := oldObj.(*v1.PipelineRun)
+	pr, ok2 := obj.(*v1.PipelineRun)
+	if ok && ok2 && limited(pr) && !old.IsDone() && pr.IsDone() {
+		c.queue.Add(concurrencyKey(pr))
+		return
+	}
+	c.enqueue(obj)
+}
+
+// enqueueDelete queues a concurrency group once a PipelineRun of it is deleted, since it may free a slot.
+func (c *queueController) enqueueDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pr, ok := obj.(*v1.PipelineRun)
+	if !ok || !limited(pr) {
+		return
+	}
+	c.queue.Add(concurrencyKey(pr))
+}
+
+// queued returns the number of running PipelineRuns of a concurrency group, and pending ones, which are sorted by
+// creation time. PipelineRuns which wait for their needs are not queued yet, and cancelled ones do not take a slot,
+// even before they are done, so PipelineRuns which superseded them are not queued behind them.
+func queued(prs []v1.PipelineRun) (int, []v1.PipelineRun) {
+	var running int
+	var pending []v1.PipelineRun
+	for _, pr := range prs {
+		switch {
+		case pr.IsDone(), waiting(&pr), pr.Spec.Status == v1.PipelineRunSpecStatusCancelled:
+		case pr.IsPending():
+			pending = append(pending, pr)
+		default:
+			running++
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		if pending[i].CreationTimestamp.Equal(&pending[j].CreationTimestamp) {
+			return pending[i].Name < pending[j].Name
+		}
+		return pending[i].CreationTimestamp.Before(&pending[j].CreationTimestamp)
+	})
+	return running, pending
+}
+
+func maxConcurrent(pr *v1.PipelineRun) (int, error) {
+	n, err := strconv.Atoi(pr.Annotations[pipelineconfig.MaxConcurrentAnnotationKey])
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse max concurrent of %s: %w", pr.Name, err)
+	}
+	return n, nil
+}
+
+// sync starts pending PipelineRuns of a concurrency group, while fewer than max concurrent ones run. PipelineRuns are
+// listed from the API server instead of the informer, so ones which were just started are counted.
+func (c *queueController) sync(ctx context.Context, key string) error {
+	ns, group, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	prs, err := listGroup(ctx, c.tektonClient, ns, group)
+	if err != nil {
+		return err
+	}
+	running, pending := queued(prs)
+	if len(pending) == 0 {
+		return nil
+	}
+	n, err := maxConcurrent(&pending[0])
+	if err != nil {
+		return err
+	}
+	logger := logging.FromContext(ctx)
+	for i := 0; i < n-running && i < len(pending); i++ {
+		pr := pending[i]
+		_, err := c.tektonClient.TektonV1().
+			PipelineRuns(ns).
+			Patch(ctx, pr.Name, types.MergePatchType, startPatch, metav1.PatchOptions{})
+		if err != nil {
+			return err
+		}
+		logger.Infow("Controller started pending pipeline run",
+			zap.String("namespace", ns),
+			zap.String("name", pr.Name),
+			zap.String("group", pr.Annotations[pipelineconfig.ConcurrencyGroupAnnotationKey]),
+		)
+	}
+	return nil
+}
+
+// NewQueueController starts pending PipelineRuns of concurrency groups with max concurrent ones, as slots free up.
+func NewQueueController(tektonClient versioned.Interface) Controller {
+	c := &queueController{newController(tektonClient, pipelineconfig.ConcurrencyLabelKey)}
+	c.syncHandler = c.sync
+	c.informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueue,
+		UpdateFunc: c.enqueueUpdate,
+		DeleteFunc: c.enqueueDelete,
+	})
+	return c
+}
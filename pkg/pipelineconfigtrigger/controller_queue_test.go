@@ -0,0 +1,40 @@
+package pipelineconfigtrigger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestQueueController_Sync(t *testing.T) {
+	now := time.Now()
+	done := limitedRun("done", "", now.Add(-time.Hour))
+	done.Status.Status = duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue}}}
+	client := fake.NewSimpleClientset(
+		done,
+		limitedRun("running", "", now.Add(-time.Hour)),
+		limitedRun("third", v1.PipelineRunSpecStatusPending, now.Add(time.Minute)),
+		limitedRun("second", v1.PipelineRunSpecStatusPending, now),
+		limitedRun("first", v1.PipelineRunSpecStatusPending, now),
+	)
+	c := NewQueueController(client).(*queueController)
+	assert.Nil(t, c.sync(context.TODO(), "tekton/a"))
+
+	for name, status := range map[string]v1.PipelineRunSpecStatus{
+		"first":  "",
+		"second": v1.PipelineRunSpecStatusPending,
+		"third":  v1.PipelineRunSpecStatusPending,
+	} {
+		pr, err := client.TektonV1().PipelineRuns("tekton").Get(context.TODO(), name, metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, status, pr.Spec.Status, name)
+	}
+}
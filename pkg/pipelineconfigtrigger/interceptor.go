@@ -2,6 +2,11 @@ package pipelineconfigtrigger
 
 import (
 	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfigreporter"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/triggers"
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
@@ -16,6 +21,17 @@ var _ v1beta1.InterceptorInterface = (*interceptor)(nil)
 type interceptor struct {
 	service  Service
 	resolver pipelineresolver.Resolver
+	reporter pipelineconfigreporter.Reporter
+}
+
+func (i *interceptor) report(ctx context.Context, meta *pipelineresolver.Metadata, err error) {
+	if i.reporter == nil {
+		return
+	}
+	rerr := i.reporter.Report(ctx, meta, err)
+	if rerr != nil {
+		logging.FromContext(ctx).Warnw("Interceptor failed to report config error", zap.Error(rerr))
+	}
 }
 
 func (i *interceptor) Process(ctx context.Context, req *v1beta1.InterceptorRequest) *v1beta1.InterceptorResponse {
@@ -26,21 +42,39 @@ func (i *interceptor) Process(ctx context.Context, req *v1beta1.InterceptorReque
 		logger.Errorw("Interceptor failed to unmarshal request", zap.Error(err))
 		return interceptors.Fail(codes.Internal, "Request body is malformed")
 	}
+	meta := &pipelineresolver.Metadata{
+		Header:         req.Header,
+		Extensions:     req.Extensions,
+		Params:         req.InterceptorParams,
+		Body:           body,
+		RequestURL:     rw.RequestURL(),
+		TriggerContext: req.Context,
+	}
 	cfg, err := rw.CurrentConfig()
 	if err != nil {
 		logger.Errorw("Interceptor failed to get current config", zap.Error(err))
-		return interceptors.Fail(codes.InvalidArgument, "Unable to get current config")
+		var d *pipelineconfig.Diagnostic
+		if errors.As(err, &d) {
+			i.report(ctx, meta, err)
+			return interceptors.Fail(codes.InvalidArgument, fmt.Sprintf("Unable to get current config: %v", d))
+		}
+		// Missing or malformed extensions are failures of upstream interceptors, rather than of config authors.
+		return interceptors.Fail(codes.Internal, "Unable to get current config")
+	}
+	meta.ChangedFiles, err = rw.ChangedFiles()
+	if err != nil {
+		logger.Errorw("Interceptor failed to get changed files", zap.Error(err))
+		return interceptors.Fail(codes.InvalidArgument, "Unable to get changed files")
 	}
-	prs, err := cfg.PipelineRuns(
-		pipelineresolver.WithResolver(ctx, i.resolver),
-		&pipelineresolver.Metadata{
-			Header:     req.Header,
-			Extensions: req.Extensions,
-			Params:     req.InterceptorParams,
-			Body:       body,
-		})
+	prs, err := cfg.PipelineRuns(pipelineresolver.WithResolver(ctx, i.resolver), meta)
 	if err != nil {
 		logger.Errorw("Interceptor failed to get pipeline runs from config", zap.Error(err))
+		var d *pipelineconfig.Diagnostic
+		if errors.As(err, &d) {
+			// Errors of config fields are mistakes of its authors, so they are shown to them.
+			i.report(ctx, meta, err)
+			return interceptors.Fail(codes.InvalidArgument, fmt.Sprintf("Unable to get pipeline runs from config: %v", d))
+		}
 		return interceptors.Fail(codes.Internal, "Unable to get pipeline runs from config")
 	}
 	err = i.service.Create(ctx, prs...)
@@ -59,9 +93,11 @@ func (i *interceptor) Process(ctx context.Context, req *v1beta1.InterceptorReque
 func NewInterceptor(
 	service Service,
 	resolver pipelineresolver.Resolver,
+	reporter pipelineconfigreporter.Reporter,
 ) v1beta1.InterceptorInterface {
 	return &interceptor{
 		service:  service,
 		resolver: resolver,
+		reporter: reporter,
 	}
 }
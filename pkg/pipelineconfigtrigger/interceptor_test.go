@@ -0,0 +1,68 @@
+package pipelineconfigtrigger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/stretchr/testify/assert"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"google.golang.org/grpc/codes"
+)
+
+type fakeService struct {
+	pipelineRuns []*v1.PipelineRun
+}
+
+func (s *fakeService) Create(_ context.Context, pipelineRuns ...*v1.PipelineRun) error {
+	s.pipelineRuns = append(s.pipelineRuns, pipelineRuns...)
+	return nil
+}
+
+type fakeReporter struct {
+	err error
+}
+
+func (r *fakeReporter) Report(_ context.Context, _ *pipelineresolver.Metadata, err error) error {
+	r.err = err
+	return nil
+}
+
+func TestInterceptor_Process_Report(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	for name, tc := range map[string]struct {
+		extensions map[string]interface{}
+		code       codes.Code
+		reported   bool
+	}{
+		"missing config": {
+			extensions: map[string]interface{}{},
+			code:       codes.Internal,
+		},
+		"malformed config": {
+			extensions: map[string]interface{}{pipelineconfig.ConfigKey: `{"triggers": [`},
+			code:       codes.Internal,
+		},
+		"invalid filter": {
+			extensions: map[string]interface{}{
+				pipelineconfig.ConfigKey: `{"triggers": [{"name": "main", "filter": "bdy.ref == 'main'"}]}`,
+			},
+			code:     codes.InvalidArgument,
+			reported: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			reporter := &fakeReporter{}
+			i := NewInterceptor(&fakeService{}, r, reporter)
+			res := i.Process(context.TODO(), &v1beta1.InterceptorRequest{
+				Body:       `{}`,
+				Extensions: tc.extensions,
+			})
+			assert.Equal(t, tc.code, res.Status.Code)
+			assert.Equal(t, tc.reported, reporter.err != nil)
+		})
+	}
+}
@@ -2,15 +2,31 @@ package pipelineconfigtrigger
 
 import (
 	"context"
+	"fmt"
+	"time"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
 	"github.com/hashicorp/go-multierror"
 	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	"go.uber.org/zap"
 	"gopkg.in/go-playground/pool.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"knative.dev/pkg/logging"
 )
 
+const (
+	// maxNameLength is the maximum length of a PipelineRun name, which is used as a label value of its TaskRuns.
+	maxNameLength = 63
+	// idempotentNameSuffixLength is the length of a prefix of an idempotency key, which is appended to a name.
+	idempotentNameSuffixLength = 16
+)
+
+var cancelPatch = []byte(fmt.Sprintf(`{"spec":{"status":%q}}`, v1.PipelineRunSpecStatusCancelled))
+
 type Service interface {
 	Create(ctx context.Context, pipelineRuns ...*v1.PipelineRun) error
 }
@@ -18,16 +34,73 @@ type Service interface {
 type service struct {
 	tektonClient versioned.Interface
 	pool         pool.Pool
+	dedupeWindow time.Duration
 }
 
 var _ Service = (*service)(nil)
 
+// idempotentName returns a name of pipelineRun derived from its idempotency key, so a PipelineRun of a redelivered
+// event fails to be created with AlreadyExists, or an empty name if it has a name or no key.
+func idempotentName(pipelineRun *v1.PipelineRun) string {
+	key := pipelineRun.Labels[pipelineconfig.IdempotencyKeyLabelKey]
+	if key == "" || pipelineRun.Name != "" || pipelineRun.GenerateName == "" {
+		return ""
+	}
+	key = key[:min(len(key), idempotentNameSuffixLength)]
+	prefix := pipelineRun.GenerateName
+	if len(prefix)+len(key) > maxNameLength {
+		prefix = prefix[:maxNameLength-len(key)]
+	}
+	return prefix + key
+}
+
+// duplicate reports whether a PipelineRun with the name of pipelineRun was created within the dedupe window.
+func (s *service) duplicate(ctx context.Context, pipelineRun *v1.PipelineRun) bool {
+	pr, err := s.tektonClient.TektonV1().
+		PipelineRuns(pipelineRun.Namespace).
+		Get(ctx, pipelineRun.Name, metav1.GetOptions{})
+	if err != nil {
+		logging.FromContext(ctx).Warnw("Service failed to get existing pipeline run",
+			zap.String("namespace", pipelineRun.Namespace),
+			zap.String("name", pipelineRun.Name),
+			zap.Error(err),
+		)
+		return false
+	}
+	return pr.CreationTimestamp.Time.After(time.Now().Add(-s.dedupeWindow))
+}
+
 func (s *service) create(ctx context.Context, pipelineRun *v1.PipelineRun) func(wu pool.WorkUnit) (interface{}, error) {
 	return func(wu pool.WorkUnit) (interface{}, error) {
 		if wu.IsCancelled() {
 			return nil, nil
 		}
 		logger := logging.FromContext(ctx)
+		if name := idempotentName(pipelineRun); s.dedupeWindow > 0 && name != "" {
+			pr := pipelineRun.DeepCopy()
+			pr.Name = name
+			pr.GenerateName = ""
+			created, err := s.tektonClient.TektonV1().
+				PipelineRuns(pr.Namespace).
+				Create(ctx, pr, metav1.CreateOptions{})
+			if !apierrors.IsAlreadyExists(err) {
+				logger.Infow("Service started pipeline run",
+					zap.String("namespace", pr.Namespace),
+					zap.String("name", pr.Name),
+					zap.Any("labels", pr.Labels),
+					zap.Any("annotations", pr.Annotations),
+				)
+				return created, err
+			}
+			if s.duplicate(ctx, pr) {
+				logger.Infow("Service skipped duplicate pipeline run",
+					zap.String("namespace", pr.Namespace),
+					zap.String("name", pr.Name),
+				)
+				return nil, nil
+			}
+			// The existing PipelineRun is older than the dedupe window, so a new one gets a generated name.
+		}
 		logger.Infow("Service started pipeline run",
 			zap.String("namespace", pipelineRun.Namespace),
 			zap.String("name", pipelineRun.Name),
@@ -41,7 +114,95 @@ func (s *service) create(ctx context.Context, pipelineRun *v1.PipelineRun) func(
 	}
 }
 
+// listGroup lists PipelineRuns of a concurrency group in namespace.
+func listGroup(ctx context.Context, tektonClient versioned.Interface, namespace, group string) ([]v1.PipelineRun, error) {
+	prs, err := tektonClient.TektonV1().PipelineRuns(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{pipelineconfig.ConcurrencyLabelKey: group}).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prs.Items, nil
+}
+
+// cancel patches running PipelineRuns of a concurrency group in namespace to `Cancelled`, except ones with keys, which
+// are of the same delivery of an event, so a redelivered event does not cancel its own PipelineRuns.
+func (s *service) cancel(ctx context.Context, namespace, group string, keys map[string]bool) error {
+	logger := logging.FromContext(ctx)
+	prs, err := listGroup(ctx, s.tektonClient, namespace, group)
+	if err != nil {
+		return err
+	}
+	me := new(multierror.Error)
+	for _, pr := range prs {
+		if pr.IsDone() || pr.IsCancelled() || pr.Spec.Status == v1.PipelineRunSpecStatusCancelled {
+			continue
+		}
+		if keys[pr.Labels[pipelineconfig.IdempotencyKeyLabelKey]] {
+			continue
+		}
+		_, err := s.tektonClient.TektonV1().
+			PipelineRuns(namespace).
+			Patch(ctx, pr.Name, types.MergePatchType, cancelPatch, metav1.PatchOptions{})
+		if err != nil {
+			me = multierror.Append(me, err)
+			continue
+		}
+		logger.Infow("Service cancelled pipeline run",
+			zap.String("namespace", namespace),
+			zap.String("name", pr.Name),
+			zap.String("group", pr.Annotations[pipelineconfig.ConcurrencyGroupAnnotationKey]),
+		)
+	}
+	return me.ErrorOrNil()
+}
+
+// cancelInProgress cancels running PipelineRuns of concurrency groups of pipelineRuns which cancel them, once per
+// group.
+func (s *service) cancelInProgress(ctx context.Context, pipelineRuns []*v1.PipelineRun) {
+	keys := map[string]bool{}
+	for _, pipelineRun := range pipelineRuns {
+		if key := pipelineRun.Labels[pipelineconfig.IdempotencyKeyLabelKey]; key != "" {
+			keys[key] = true
+		}
+	}
+	cancelled := map[string]bool{}
+	for _, pipelineRun := range pipelineRuns {
+		group := pipelineRun.Labels[pipelineconfig.ConcurrencyLabelKey]
+		if group == "" || pipelineRun.Annotations[pipelineconfig.CancelInProgressAnnotationKey] != "true" {
+			continue
+		}
+		key := concurrencyKey(pipelineRun)
+		if cancelled[key] {
+			continue
+		}
+		cancelled[key] = true
+		err := s.cancel(ctx, pipelineRun.Namespace, group, keys)
+		if err != nil {
+			// New PipelineRuns are created anyway, since superseded ones only waste resources.
+			logging.FromContext(ctx).Warnw("Service failed to cancel pipeline runs",
+				zap.String("namespace", pipelineRun.Namespace),
+				zap.String("group", pipelineRun.Annotations[pipelineconfig.ConcurrencyGroupAnnotationKey]),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// queue creates PipelineRuns of concurrency groups with max concurrent ones as pending, so the queue controller, which
+// runs in a single replica, is the only one to start them, and concurrent events or replicas of the interceptor do not
+// start more of them than max concurrent.
+func queue(pipelineRuns []*v1.PipelineRun) {
+	for _, pipelineRun := range pipelineRuns {
+		if limited(pipelineRun) {
+			pipelineRun.Spec.Status = v1.PipelineRunSpecStatusPending
+		}
+	}
+}
+
 func (s *service) Create(ctx context.Context, pipelineRuns ...*v1.PipelineRun) error {
+	s.cancelInProgress(ctx, pipelineRuns)
+	queue(pipelineRuns)
 	me := new(multierror.Error)
 	batch := s.pool.Batch()
 	for _, pipelineRun := range pipelineRuns {
@@ -59,9 +220,11 @@ func (s *service) Create(ctx context.Context, pipelineRuns ...*v1.PipelineRun) e
 func NewService(
 	tektonClient versioned.Interface,
 	pool pool.Pool,
+	dedupeWindow time.Duration,
 ) Service {
 	return &service{
 		tektonClient: tektonClient,
 		pool:         pool,
+		dedupeWindow: dedupeWindow,
 	}
 }
@@ -0,0 +1,181 @@
+package pipelineconfigtrigger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/stretchr/testify/assert"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	"gopkg.in/go-playground/pool.v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func concurrentRun(name, group string, status corev1.ConditionStatus) *v1.PipelineRun {
+	pr := &v1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "tekton",
+			Name:      name,
+			Labels:    map[string]string{pipelineconfig.ConcurrencyLabelKey: group},
+			Annotations: map[string]string{
+				pipelineconfig.CancelInProgressAnnotationKey: "true",
+			},
+		},
+	}
+	if status != "" {
+		pr.Status.Status = duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: status}}}
+	}
+	return pr
+}
+
+func TestService_Create_CancelInProgress(t *testing.T) {
+	// A redelivered event does not cancel PipelineRuns of its own delivery.
+	redelivered := concurrentRun("redelivered", "a", corev1.ConditionUnknown)
+	redelivered.Labels[pipelineconfig.IdempotencyKeyLabelKey] = "key"
+	client := fake.NewSimpleClientset(
+		concurrentRun("running", "a", corev1.ConditionUnknown),
+		concurrentRun("done", "a", corev1.ConditionTrue),
+		concurrentRun("other", "b", corev1.ConditionUnknown),
+		redelivered,
+	)
+	p := pool.NewLimited(1)
+	defer p.Close()
+	s := NewService(client, p, 0)
+	pr := concurrentRun("new", "a", "")
+	pr.Labels[pipelineconfig.IdempotencyKeyLabelKey] = "key"
+	err := s.Create(context.TODO(), pr)
+	assert.Nil(t, err)
+
+	for name, status := range map[string]v1.PipelineRunSpecStatus{
+		"running":     v1.PipelineRunSpecStatusCancelled,
+		"done":        "",
+		"other":       "",
+		"redelivered": "",
+		"new":         "",
+	} {
+		pr, err := client.TektonV1().PipelineRuns("tekton").Get(context.TODO(), name, metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, status, pr.Spec.Status, name)
+	}
+}
+
+func limitedRun(name string, spec v1.PipelineRunSpecStatus, created time.Time) *v1.PipelineRun {
+	pr := concurrentRun(name, "a", "")
+	delete(pr.Annotations, pipelineconfig.CancelInProgressAnnotationKey)
+	pr.Annotations[pipelineconfig.MaxConcurrentAnnotationKey] = "2"
+	pr.CreationTimestamp = metav1.NewTime(created)
+	pr.Spec.Status = spec
+	return pr
+}
+
+func TestService_Create_Queue(t *testing.T) {
+	now := time.Now()
+	client := fake.NewSimpleClientset(limitedRun("running", "", now))
+	p := pool.NewLimited(1)
+	defer p.Close()
+	s := NewService(client, p, 0)
+	err := s.Create(context.TODO(), limitedRun("first", "", now), limitedRun("second", "", now))
+	assert.Nil(t, err)
+
+	// Even the first one is pending, though the group has a free slot, since only the queue controller starts them.
+	for name, status := range map[string]v1.PipelineRunSpecStatus{
+		"first":  v1.PipelineRunSpecStatusPending,
+		"second": v1.PipelineRunSpecStatusPending,
+	} {
+		pr, err := client.TektonV1().PipelineRuns("tekton").Get(context.TODO(), name, metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, status, pr.Spec.Status, name)
+	}
+}
+
+func TestService_Create_Dedupe(t *testing.T) {
+	now := time.Now()
+	keyed := func(generateName, key string) *v1.PipelineRun {
+		return &v1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    "tekton",
+				GenerateName: generateName,
+				Labels:       map[string]string{pipelineconfig.IdempotencyKeyLabelKey: key},
+			},
+		}
+	}
+	existing := func(generateName, key string, created time.Time) *v1.PipelineRun {
+		pr := keyed(generateName, key)
+		pr.Name = idempotentName(pr)
+		pr.GenerateName = ""
+		pr.CreationTimestamp = metav1.NewTime(created)
+		return pr
+	}
+	client := fake.NewSimpleClientset(
+		existing("recent-", "0123456789abcdef0123", now.Add(-time.Minute)),
+		existing("old-", "1123456789abcdef0123", now.Add(-2*time.Hour)),
+	)
+	p := pool.NewLimited(1)
+	defer p.Close()
+	s := NewService(client, p, time.Hour)
+	err := s.Create(context.TODO(),
+		keyed("recent-", "0123456789abcdef0123"),
+		keyed("old-", "1123456789abcdef0123"),
+		keyed("new-", "2123456789abcdef0123"),
+	)
+	assert.Nil(t, err)
+
+	prs, err := client.TektonV1().PipelineRuns("tekton").List(context.TODO(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	var names []string
+	for _, pr := range prs.Items {
+		names = append(names, pr.Name+pr.GenerateName)
+	}
+	// The old one is older than the dedupe window, so a new one gets a generated name.
+	assert.ElementsMatch(t, []string{
+		"recent-0123456789abcdef",
+		"old-1123456789abcdef",
+		"old-",
+		"new-2123456789abcdef",
+	}, names)
+}
+
+func TestIdempotentName(t *testing.T) {
+	pr := &v1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "a-very-long-name-of-a-pipeline-which-does-not-fit-run-",
+			Labels:       map[string]string{pipelineconfig.IdempotencyKeyLabelKey: "0123456789abcdef0123"},
+		},
+	}
+	name := idempotentName(pr)
+	assert.Len(t, name, maxNameLength)
+	assert.Equal(t, "a-very-long-name-of-a-pipeline-which-does-not-f0123456789abcdef", name)
+	pr.Name = "explicit"
+	assert.Empty(t, idempotentName(pr))
+}
+
+func TestService_Create_CancelInProgress_Queue(t *testing.T) {
+	// A cancelled PipelineRun does not take a slot while it stops, so the one which superseded it starts.
+	running := limitedRun("running", "", time.Now())
+	running.Annotations[pipelineconfig.MaxConcurrentAnnotationKey] = "1"
+	client := fake.NewSimpleClientset(running)
+	p := pool.NewLimited(1)
+	defer p.Close()
+	s := NewService(client, p, 0)
+	pr := limitedRun("new", "", time.Now())
+	pr.Annotations[pipelineconfig.MaxConcurrentAnnotationKey] = "1"
+	pr.Annotations[pipelineconfig.CancelInProgressAnnotationKey] = "true"
+	err := s.Create(context.TODO(), pr)
+	assert.Nil(t, err)
+	c := NewQueueController(client).(*queueController)
+	assert.Nil(t, c.sync(context.TODO(), "tekton/a"))
+
+	for name, status := range map[string]v1.PipelineRunSpecStatus{
+		"running": v1.PipelineRunSpecStatusCancelled,
+		"new":     "",
+	} {
+		pr, err := client.TektonV1().PipelineRuns("tekton").Get(context.TODO(), name, metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, status, pr.Spec.Status, name)
+	}
+}
@@ -1,9 +1,26 @@
 package pipelineresolver
 
+import (
+	"github.com/google/cel-go/cel"
+)
+
 type ExprInvalidError struct {
 	Err error
+	// Line and Column are a position of the first issue in the expression, Line starts at 1 and Column at 0,
+	// or zero if unknown.
+	Line   int
+	Column int
 }
 
 func (e *ExprInvalidError) Error() string {
 	return e.Err.Error()
 }
+
+func newExprInvalidError(issues *cel.Issues) *ExprInvalidError {
+	e := &ExprInvalidError{Err: issues.Err()}
+	if errs := issues.Errors(); len(errs) > 0 {
+		e.Line = errs[0].Location.Line()
+		e.Column = errs[0].Location.Column()
+	}
+	return e
+}
@@ -0,0 +1,205 @@
+package pipelineresolver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pathglob"
+	"github.com/blang/semver/v4"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/parser"
+)
+
+const branchRefPrefix = "refs/heads/"
+
+// ToolboxLib adds functions for common CI use cases:
+//
+//	gitSha(), gitRef(), branchName(), isPullRequest() - the commit, ref and branch of a GitHub push or pull request
+//	event, which are shorthands for gitSha(body), etc.
+//	semver.parse(string) - a map of major, minor, patch, prerelease and build of a version, eg. `v1.2.3-rc.1`.
+//	semver.compare(string, string) - -1, 0 or 1 if a version is less than, equal to or greater than another one.
+//	glob.match(pattern, string) - whether a path matches a glob, see `paths` of triggers.
+//	regex.capture(string, pattern) - a list of groups captured by a regexp, or an empty list if it does not match.
+//	default(expr, fallback) - fallback if expr fails or is null, eg. `default(body.pull_request.title, "")`.
+func ToolboxLib() cel.EnvOption {
+	return cel.Lib(toolboxLib{})
+}
+
+type toolboxLib struct{}
+
+// bodyMacro expands `name()` to `name(body)`.
+func bodyMacro(name string) cel.Macro {
+	return parser.NewGlobalMacro(name, 0, func(eh parser.ExprHelper, _ ast.Expr, _ []ast.Expr) (ast.Expr, *common.Error) {
+		return eh.NewCall(name, eh.NewIdent("body")), nil
+	})
+}
+
+func (toolboxLib) CompileOptions() []cel.EnvOption {
+	mapStrDyn := cel.MapType(cel.StringType, cel.DynType)
+	return []cel.EnvOption{
+		cel.Macros(
+			bodyMacro("gitSha"),
+			bodyMacro("gitRef"),
+			bodyMacro("branchName"),
+			bodyMacro("isPullRequest"),
+		),
+		cel.Function("gitSha",
+			cel.Overload("git_sha_map", []*cel.Type{mapStrDyn}, cel.StringType, cel.UnaryBinding(gitSha))),
+		cel.Function("gitRef",
+			cel.Overload("git_ref_map", []*cel.Type{mapStrDyn}, cel.StringType, cel.UnaryBinding(gitRef))),
+		cel.Function("branchName",
+			cel.Overload("branch_name_map", []*cel.Type{mapStrDyn}, cel.StringType, cel.UnaryBinding(branchName))),
+		cel.Function("isPullRequest",
+			cel.Overload("is_pull_request_map", []*cel.Type{mapStrDyn}, cel.BoolType, cel.UnaryBinding(isPullRequest))),
+		cel.Function("semver.parse",
+			cel.Overload("semver_parse_string", []*cel.Type{cel.StringType}, mapStrDyn, cel.UnaryBinding(semverParse))),
+		cel.Function("semver.compare",
+			cel.Overload("semver_compare_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.IntType,
+				cel.BinaryBinding(semverCompare))),
+		cel.Function("glob.match",
+			cel.Overload("glob_match_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(globMatch))),
+		cel.Function("regex.capture",
+			cel.Overload("regex_capture_string_string", []*cel.Type{cel.StringType, cel.StringType},
+				cel.ListType(cel.StringType), cel.BinaryBinding(regexCapture))),
+		cel.Function("default",
+			cel.Overload("default_dyn_dyn", []*cel.Type{cel.DynType, cel.DynType}, cel.DynType,
+				cel.OverloadIsNonStrict(), cel.BinaryBinding(defaultValue))),
+	}
+}
+
+func (toolboxLib) ProgramOptions() []cel.ProgramOption {
+	return nil
+}
+
+// lookup returns a value of nested keys of a map, eg. `pull_request.head.sha`.
+func lookup(v ref.Val, keys ...string) (interface{}, bool) {
+	for _, k := range keys {
+		m, ok := v.(traits.Mapper)
+		if !ok {
+			return nil, false
+		}
+		v, ok = m.Find(types.String(k))
+		if !ok {
+			return nil, false
+		}
+	}
+	if v == nil || v == types.NullValue {
+		return nil, false
+	}
+	return v.Value(), true
+}
+
+func lookupString(v ref.Val, keys ...string) (string, bool) {
+	i, ok := lookup(v, keys...)
+	if !ok {
+		return "", false
+	}
+	s, ok := i.(string)
+	return s, ok && s != ""
+}
+
+func gitSha(body ref.Val) ref.Val {
+	for _, keys := range [][]string{{"pull_request", "head", "sha"}, {"after"}, {"head_commit", "id"}} {
+		if s, ok := lookupString(body, keys...); ok {
+			return types.String(s)
+		}
+	}
+	return types.NewErr("unable to find commit SHA of event")
+}
+
+func gitRef(body ref.Val) ref.Val {
+	if n, ok := lookup(body, "pull_request", "number"); ok {
+		switch n := n.(type) {
+		case float64:
+			return types.String(fmt.Sprintf("refs/pull/%d/head", int64(n)))
+		case int64:
+			return types.String(fmt.Sprintf("refs/pull/%d/head", n))
+		}
+	}
+	if s, ok := lookupString(body, "ref"); ok {
+		return types.String(s)
+	}
+	return types.NewErr("unable to find ref of event")
+}
+
+func branchName(body ref.Val) ref.Val {
+	if s, ok := lookupString(body, "pull_request", "head", "ref"); ok {
+		return types.String(s)
+	}
+	if s, ok := lookupString(body, "ref"); ok {
+		if !strings.HasPrefix(s, branchRefPrefix) {
+			return types.NewErr("ref %s of event is not a branch", s)
+		}
+		return types.String(strings.TrimPrefix(s, branchRefPrefix))
+	}
+	return types.NewErr("unable to find branch of event")
+}
+
+func isPullRequest(body ref.Val) ref.Val {
+	_, ok := lookup(body, "pull_request")
+	return types.Bool(ok)
+}
+
+func semverParse(val ref.Val) ref.Val {
+	v, err := semver.ParseTolerant(string(val.(types.String)))
+	if err != nil {
+		return types.NewErr("unable to parse version: %v", err)
+	}
+	pre := make([]string, 0, len(v.Pre))
+	for _, p := range v.Pre {
+		pre = append(pre, p.String())
+	}
+	return types.DefaultTypeAdapter.NativeToValue(map[string]interface{}{
+		"major":      int64(v.Major),
+		"minor":      int64(v.Minor),
+		"patch":      int64(v.Patch),
+		"prerelease": strings.Join(pre, "."),
+		"build":      strings.Join(v.Build, "."),
+	})
+}
+
+func semverCompare(lhs, rhs ref.Val) ref.Val {
+	l, err := semver.ParseTolerant(string(lhs.(types.String)))
+	if err != nil {
+		return types.NewErr("unable to parse version: %v", err)
+	}
+	r, err := semver.ParseTolerant(string(rhs.(types.String)))
+	if err != nil {
+		return types.NewErr("unable to parse version: %v", err)
+	}
+	return types.Int(l.Compare(r))
+}
+
+func globMatch(pattern, name ref.Val) ref.Val {
+	ok, err := pathglob.Match(string(pattern.(types.String)), string(name.(types.String)))
+	if err != nil {
+		return types.NewErr("unable to match glob: %v", err)
+	}
+	return types.Bool(ok)
+}
+
+func regexCapture(val, pattern ref.Val) ref.Val {
+	re, err := regexp.Compile(string(pattern.(types.String)))
+	if err != nil {
+		return types.NewErr("unable to compile regexp: %v", err)
+	}
+	groups := []string{}
+	if m := re.FindStringSubmatch(string(val.(types.String))); m != nil {
+		groups = m[1:]
+	}
+	return types.NewStringList(types.DefaultTypeAdapter, groups)
+}
+
+func defaultValue(val, fallback ref.Val) ref.Val {
+	if types.IsUnknownOrError(val) || val == types.NullValue {
+		return fallback
+	}
+	return val
+}
@@ -0,0 +1,114 @@
+package pipelineresolver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+// KubeAllowlist is a list of names of Secrets and ConfigMaps which expressions may read.
+type KubeAllowlist struct {
+	Secrets    []string
+	ConfigMaps []string
+}
+
+// kubeLib reads Secrets and ConfigMaps by listers of informers, which are scoped to a single allowed name.
+type kubeLib struct {
+	secrets    map[string]corev1listers.SecretNamespaceLister
+	configMaps map[string]corev1listers.ConfigMapNamespaceLister
+}
+
+// NewKubeLib starts an informer of every allowed Secret and ConfigMap in namespace, and returns `secret(name, key)`
+// and `configMap(name, key)` functions, which read values of them, eg. `configMap("registries", body.repository.name)`.
+// Informers watch a single object by its name, so RBAC rules may grant access to allowed objects only.
+func NewKubeLib(ctx context.Context, client kubernetes.Interface, namespace string, allowed KubeAllowlist) (cel.EnvOption, error) {
+	l := &kubeLib{
+		secrets:    map[string]corev1listers.SecretNamespaceLister{},
+		configMaps: map[string]corev1listers.ConfigMapNamespaceLister{},
+	}
+	var factories []informers.SharedInformerFactory
+	factoryFor := func(name string) informers.SharedInformerFactory {
+		factory := informers.NewSharedInformerFactoryWithOptions(client, 0,
+			informers.WithNamespace(namespace),
+			informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+				o.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+			}),
+		)
+		factories = append(factories, factory)
+		return factory
+	}
+	for _, n := range allowed.Secrets {
+		l.secrets[n] = factoryFor(n).Core().V1().Secrets().Lister().Secrets(namespace)
+	}
+	for _, n := range allowed.ConfigMaps {
+		l.configMaps[n] = factoryFor(n).Core().V1().ConfigMaps().Lister().ConfigMaps(namespace)
+	}
+	for _, factory := range factories {
+		factory.Start(ctx.Done())
+		for t, ok := range factory.WaitForCacheSync(ctx.Done()) {
+			if !ok {
+				return nil, fmt.Errorf("failed to sync informer of %v", t)
+			}
+		}
+	}
+	return cel.Lib(l), nil
+}
+
+func (l *kubeLib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("secret",
+			cel.Overload("secret_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.StringType,
+				cel.BinaryBinding(l.secret))),
+		cel.Function("configMap",
+			cel.Overload("config_map_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.StringType,
+				cel.BinaryBinding(l.configMap))),
+	}
+}
+
+func (l *kubeLib) ProgramOptions() []cel.ProgramOption {
+	return nil
+}
+
+func (l *kubeLib) secret(name, key ref.Val) ref.Val {
+	n, k := string(name.(types.String)), string(key.(types.String))
+	lister, ok := l.secrets[n]
+	if !ok {
+		return types.NewErr("secret %s is not allowed", n)
+	}
+	s, err := lister.Get(n)
+	if err != nil {
+		return types.NewErr("unable to get secret %s: %v", n, err)
+	}
+	v, ok := s.Data[k]
+	if !ok {
+		return types.NewErr("secret %s has no key %s", n, k)
+	}
+	return types.String(v)
+}
+
+func (l *kubeLib) configMap(name, key ref.Val) ref.Val {
+	n, k := string(name.(types.String)), string(key.(types.String))
+	lister, ok := l.configMaps[n]
+	if !ok {
+		return types.NewErr("config map %s is not allowed", n)
+	}
+	cm, err := lister.Get(n)
+	if err != nil {
+		return types.NewErr("unable to get config map %s: %v", n, err)
+	}
+	if v, ok := cm.Data[k]; ok {
+		return types.String(v)
+	}
+	if v, ok := cm.BinaryData[k]; ok {
+		return types.String(v)
+	}
+	return types.NewErr("config map %s has no key %s", n, k)
+}
@@ -0,0 +1,88 @@
+package pipelineresolver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newKubeLibResolver(t *testing.T) Resolver {
+	client := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "tekton", Name: "registry"},
+			Data:       map[string][]byte{"password": []byte("s3cr3t")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "tekton", Name: "github"},
+			Data:       map[string][]byte{"token": []byte("ghp")},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "tekton", Name: "registries"},
+			Data:       map[string]string{"tekton-toolbox": "gcr.io/toolbox"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"},
+			Data:       map[string]string{"foo": "bar"},
+		},
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	lib, err := NewKubeLib(ctx, client, "tekton", KubeAllowlist{
+		Secrets:    []string{"registry"},
+		ConfigMaps: []string{"registries", "other"},
+	})
+	assert.Nil(t, err)
+	r, err := NewCachedCelResolver(DefaultProgramCacheSize, lib)
+	assert.Nil(t, err)
+	return r
+}
+
+func TestKubeLib(t *testing.T) {
+	r := newKubeLibResolver(t)
+	meta := &Metadata{
+		Body: map[string]interface{}{
+			"repository": map[string]interface{}{"name": "tekton-toolbox"},
+		},
+	}
+	val, err := r.ValueOf(context.TODO(), meta, "configMap('registries', body.repository.name)")
+	assert.Nil(t, err)
+	assert.Equal(t, "gcr.io/toolbox", val)
+	val, err = r.ValueOf(context.TODO(), meta, "secret('registry', 'password')")
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cr3t", val)
+}
+
+func TestKubeLib_Errors(t *testing.T) {
+	r := newKubeLibResolver(t)
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{"secret('github', 'token')", "secret github is not allowed"},
+		{"secret('registry', 'username')", "secret registry has no key username"},
+		{"configMap('other', 'foo')", "unable to get config map other"},
+		{"configMap('registries', 'foo')", "config map registries has no key foo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := r.ValueOf(context.TODO(), &Metadata{}, tt.expr)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestKubeLib_NotAllowed(t *testing.T) {
+	// Without NewKubeLib, secret and configMap are declared, but nothing is allowed.
+	r, err := NewCelResolver()
+	assert.Nil(t, err)
+	_, err = r.ValueOf(context.TODO(), &Metadata{}, "secret('registry', 'password')")
+	assert.ErrorContains(t, err, "secret registry is not allowed")
+	_, err = r.ValueOf(context.TODO(), &Metadata{}, "configMap('registries', 'tekton-toolbox')")
+	assert.ErrorContains(t, err, "config map registries is not allowed")
+	_, err = r.SafeValueOf(context.TODO(), &Metadata{}, "configMap('registries', 'tekton-toolbox')")
+	assert.ErrorContains(t, err, "config map registries is not allowed")
+}
@@ -0,0 +1,92 @@
+package pipelineresolver
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readPayload(t *testing.T, name string) *Metadata {
+	buf, err := os.ReadFile(filepath.Join("testdata", name))
+	assert.Nil(t, err)
+	var body map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf, &body))
+	return &Metadata{Body: body}
+}
+
+func TestToolboxLib_Git(t *testing.T) {
+	tests := []struct {
+		payload string
+		expr    string
+		want    interface{}
+	}{
+		{"github_pull_request.json", "gitSha()", "6dcb09b5b57875f334f61aebed695e2e4193db5e"},
+		{"github_pull_request.json", "gitRef()", "refs/pull/42/head"},
+		{"github_pull_request.json", "branchName()", "feature/release"},
+		{"github_pull_request.json", "isPullRequest()", true},
+		{"github_push.json", "gitSha()", "b9a2c3d4e5f60718293a4b5c6d7e8f9012345678"},
+		{"github_push.json", "gitRef()", "refs/heads/main"},
+		{"github_push.json", "branchName()", "main"},
+		{"github_push.json", "isPullRequest()", false},
+		{"github_push.json", "gitSha(body) == body.after", true},
+		{"github_tag.json", "gitRef()", "refs/tags/v1.2.3-rc.1"},
+		{"github_tag.json", "default(branchName(), 'none')", "none"},
+	}
+	r, err := NewCelResolver()
+	assert.Nil(t, err)
+	for _, tt := range tests {
+		t.Run(tt.payload+" "+tt.expr, func(t *testing.T) {
+			val, err := r.ValueOf(context.TODO(), readPayload(t, tt.payload), tt.expr)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, val)
+		})
+	}
+}
+
+func TestToolboxLib_BranchName_Tag(t *testing.T) {
+	r, err := NewCelResolver()
+	assert.Nil(t, err)
+	_, err = r.ValueOf(context.TODO(), readPayload(t, "github_tag.json"), "branchName()")
+	assert.ErrorContains(t, err, "is not a branch")
+}
+
+func TestToolboxLib_Functions(t *testing.T) {
+	tests := []struct {
+		expr string
+		want interface{}
+	}{
+		{"semver.parse(gitRef().split('/')[2]).major", int64(1)},
+		{"semver.parse('v1.2.3-rc.1+build.5').prerelease", "rc.1"},
+		{"semver.parse('1.2.3+build.5').build", "build.5"},
+		{"semver.compare('v1.10.0', 'v1.9.0')", int64(1)},
+		{"semver.compare('1.2.3-rc.1', '1.2.3')", int64(-1)},
+		{"semver.compare('1.2.3', 'v1.2.3')", int64(0)},
+		{"glob.match('docs/**/*.md', 'docs/guide/intro.md')", true},
+		{"glob.match('docs/*.md', 'src/main.go')", false},
+		{"regex.capture(gitRef(), '^refs/tags/v(\\\\d+)\\\\.(\\\\d+)')", []interface{}{"1", "2"}},
+		{"size(regex.capture(gitRef(), '^refs/heads/(.*)$'))", int64(0)},
+		{"default(body.pull_request.title, 'none')", "none"},
+		{"default(body.missing, 'none')", "none"},
+		{"default(body.ref, 'none')", "refs/tags/v1.2.3-rc.1"},
+	}
+	r, err := NewCelResolver()
+	assert.Nil(t, err)
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			val, err := r.ValueOf(context.TODO(), readPayload(t, "github_tag.json"), tt.expr)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, val)
+		})
+	}
+}
+
+func TestToolboxLib_SemverInvalid(t *testing.T) {
+	r, err := NewCelResolver()
+	assert.Nil(t, err)
+	_, err = r.ValueOf(context.TODO(), &Metadata{}, "semver.compare('main', '1.0.0')")
+	assert.ErrorContains(t, err, "unable to parse version")
+}
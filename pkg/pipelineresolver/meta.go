@@ -1,10 +1,17 @@
 package pipelineresolver
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+)
 
 type Metadata struct {
-	Header     http.Header
-	Extensions map[string]interface{}
-	Body       map[string]interface{}
-	Params     map[string]interface{}
+	Header         http.Header
+	Extensions     map[string]interface{}
+	Body           map[string]interface{}
+	Params         map[string]interface{}
+	ChangedFiles   []string
+	RequestURL     string
+	TriggerContext *v1beta1.TriggerContext
 }
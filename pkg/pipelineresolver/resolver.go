@@ -8,3 +8,9 @@ type Resolver interface {
 	ValueOf(ctx context.Context, meta *Metadata, val string) (interface{}, error)
 	SafeValueOf(ctx context.Context, meta *Metadata, val string) (interface{}, error)
 }
+
+// Referrer is a Resolver which reports whether a value refers to a variable, eg. `changedFiles`, so the variable is
+// only fetched if a config needs it.
+type Referrer interface {
+	Refers(val, name string) bool
+}
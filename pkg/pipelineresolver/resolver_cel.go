@@ -2,58 +2,177 @@ package pipelineresolver
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/google/cel-go/cel"
 	"github.com/google/cel-go/checker/decls"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
 	celext "github.com/google/cel-go/ext"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
 	triggerscel "github.com/tektoncd/triggers/pkg/interceptors/cel"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/lru"
 )
 
-func NewCelEnv() (*cel.Env, error) {
+const (
+	// DefaultProgramCacheSize is the number of compiled expressions kept by CelResolver, which is enough for every
+	// label, annotation, param and filter of a few large configs.
+	DefaultProgramCacheSize = 1000
+
+	programCacheResultHit  = "hit"
+	programCacheResultMiss = "miss"
+)
+
+var programCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "cel_program_cache_requests_total",
+	Help: "Number of CEL program requests by cache result: hit or miss.",
+}, []string{"result"})
+
+func init() {
+	prometheus.MustRegister(programCacheRequests)
+}
+
+// NewCelEnv returns an environment with variables of Metadata and functions of ToolboxLib, and opts, eg. KubeLib.
+func NewCelEnv(opts ...cel.EnvOption) (*cel.Env, error) {
 	mapStrDyn := decls.NewMapType(decls.String, decls.Dyn)
-	return cel.NewEnv(
+	return cel.NewEnv(append([]cel.EnvOption{
 		triggerscel.Triggers(context.Background(), metav1.NamespaceAll, nil),
 		celext.Strings(),
 		celext.Encoders(),
+		ToolboxLib(),
+		// secret and configMap are always declared, so they fail unless a lib of NewKubeLib allows something.
+		cel.Lib(&kubeLib{}),
 		cel.Declarations(
 			decls.NewVar("body", mapStrDyn),
 			decls.NewVar("header", mapStrDyn),
 			decls.NewVar("extensions", mapStrDyn),
 			decls.NewVar("requestURL", decls.String),
 			decls.NewVar("params", mapStrDyn),
-		))
+			decls.NewVar("changedFiles", decls.NewListType(decls.String)),
+			decls.NewVar("context", decls.NewMapType(decls.String, decls.String)),
+		),
+	}, opts...)...)
+}
+
+// program is a compiled expression, or an ExprInvalidError, since most values of a config are literals which are
+// not valid expressions.
+type program struct {
+	prg cel.Program
+	err error
+}
+
+// triggerContext has every key of v1beta1.TriggerContext, which are empty if it is unknown.
+func triggerContext(c *v1beta1.TriggerContext) map[string]string {
+	if c == nil {
+		c = &v1beta1.TriggerContext{}
+	}
+	return map[string]string{
+		"event_url":  c.EventURL,
+		"event_id":   c.EventID,
+		"trigger_id": c.TriggerID,
+	}
 }
 
 type CelResolver struct {
 	env *cel.Env
+	// programs is safe for concurrent use, and caching is disabled if it is nil.
+	programs *lru.Cache
 }
 
 var _ Resolver = (*CelResolver)(nil)
+var _ Referrer = (*CelResolver)(nil)
 
-func (r *CelResolver) ValueOf(_ context.Context, meta *Metadata, val string) (interface{}, error) {
+func (r *CelResolver) compile(val string) (cel.Program, error) {
 	ast, issues := r.env.Parse(val)
 	if issues != nil && issues.Err() != nil {
-		return nil, &ExprInvalidError{issues.Err()}
+		return nil, newExprInvalidError(issues)
 	}
 	ast, issues = r.env.Check(ast)
 	if issues != nil && issues.Err() != nil {
-		return nil, &ExprInvalidError{issues.Err()}
+		return nil, newExprInvalidError(issues)
+	}
+	return r.env.Program(ast)
+}
+
+func (r *CelResolver) program(val string) (cel.Program, error) {
+	if r.programs == nil {
+		return r.compile(val)
+	}
+	if v, ok := r.programs.Get(val); ok {
+		programCacheRequests.WithLabelValues(programCacheResultHit).Inc()
+		p := v.(*program)
+		return p.prg, p.err
+	}
+	programCacheRequests.WithLabelValues(programCacheResultMiss).Inc()
+	prg, err := r.compile(val)
+	var e *ExprInvalidError
+	if err == nil || errors.As(err, &e) {
+		r.programs.Add(val, &program{prg: prg, err: err})
+	}
+	return prg, err
+}
+
+// Refers reports whether val is an expression with identifier name. A value which is not a valid expression is a
+// literal, which refers to nothing.
+func (r *CelResolver) Refers(val, name string) bool {
+	ast, issues := r.env.Parse(val)
+	if issues != nil && issues.Err() != nil {
+		return false
 	}
-	prg, err := r.env.Program(ast)
+	var found bool
+	celast.PostOrderVisit(ast.NativeRep().Expr(), celast.NewExprVisitor(func(e celast.Expr) {
+		if e.Kind() == celast.IdentKind && e.AsIdent() == name {
+			found = true
+		}
+	}))
+	return found
+}
+
+func (r *CelResolver) ValueOf(_ context.Context, meta *Metadata, val string) (interface{}, error) {
+	prg, err := r.program(val)
 	if err != nil {
 		return nil, err
 	}
 	i, _, err := prg.Eval(map[string]interface{}{
-		"body":       meta.Body,
-		"header":     meta.Header,
-		"extensions": meta.Extensions,
-		"params":     meta.Params,
+		"body":         meta.Body,
+		"header":       meta.Header,
+		"extensions":   meta.Extensions,
+		"params":       meta.Params,
+		"changedFiles": meta.ChangedFiles,
+		"requestURL":   meta.RequestURL,
+		"context":      triggerContext(meta.TriggerContext),
 	})
 	if err != nil {
 		return nil, err
 	}
-	return i.Value(), nil
+	return nativeValue(i), nil
+}
+
+// nativeValue converts lists and maps to []interface{} and map[string]interface{}, since Value of a CEL list or map
+// may hold CEL values, eg. a result of `map()`.
+func nativeValue(v ref.Val) interface{} {
+	switch v := v.(type) {
+	case traits.Lister:
+		l := []interface{}{}
+		for it := v.Iterator(); it.HasNext() == types.True; {
+			l = append(l, nativeValue(it.Next()))
+		}
+		return l
+	case traits.Mapper:
+		m := map[string]interface{}{}
+		for it := v.Iterator(); it.HasNext() == types.True; {
+			k := it.Next()
+			m[fmt.Sprint(k.Value())] = nativeValue(v.Get(k))
+		}
+		return m
+	default:
+		return v.Value()
+	}
 }
 
 func (r *CelResolver) SafeValueOf(ctx context.Context, meta *Metadata, val string) (interface{}, error) {
@@ -70,11 +189,21 @@ func (r *CelResolver) SafeValueOf(ctx context.Context, meta *Metadata, val strin
 }
 
 func NewCelResolver() (Resolver, error) {
-	env, err := NewCelEnv()
+	return NewCachedCelResolver(DefaultProgramCacheSize)
+}
+
+// NewCachedCelResolver keeps up to size compiled expressions, so they are parsed and checked once instead of on
+// every webhook. Zero size disables caching.
+func NewCachedCelResolver(size int, opts ...cel.EnvOption) (Resolver, error) {
+	env, err := NewCelEnv(opts...)
 	if err != nil {
 		return nil, err
 	}
-	return &CelResolver{
+	r := &CelResolver{
 		env: env,
-	}, nil
+	}
+	if size > 0 {
+		r.programs = lru.New(size)
+	}
+	return r, nil
 }
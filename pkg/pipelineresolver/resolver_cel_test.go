@@ -2,8 +2,12 @@ package pipelineresolver
 
 import (
 	"context"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
 	"net/http"
+	"sync"
 	"testing"
 )
 
@@ -59,3 +63,155 @@ func TestCelResolver_ValueOf_Extensions(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "bar", val)
 }
+
+func TestCelResolver_ValueOf_ChangedFiles(t *testing.T) {
+	req := &Metadata{
+		ChangedFiles: []string{"services/foo/main.go"},
+	}
+	r, err := NewCelResolver()
+	assert.Nil(t, err)
+	val, err := r.ValueOf(context.TODO(), req, `changedFiles.exists(f, f.startsWith("services/foo/"))`)
+	assert.Nil(t, err)
+	assert.Equal(t, true, val)
+	val, err = r.ValueOf(context.TODO(), &Metadata{}, "size(changedFiles)")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), val)
+}
+
+func TestCelResolver_Refers(t *testing.T) {
+	r, err := NewCelResolver()
+	assert.Nil(t, err)
+	ref := r.(Referrer)
+	tests := []struct {
+		val    string
+		refers bool
+	}{
+		{"changedFiles", true},
+		{`changedFiles.exists(f, f.startsWith("docs/"))`, true},
+		{`size(changedFiles) > 0 && body.ref == "main"`, true},
+		{"body.changedFiles", false},
+		{`"changedFiles"`, false},
+		{"see changedFiles below", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.refers, ref.Refers(tt.val, "changedFiles"), tt.val)
+	}
+}
+
+func TestCelResolver_ValueOf_TriggerContext(t *testing.T) {
+	req := &Metadata{
+		RequestURL: "https://el.tekton.dev/",
+		TriggerContext: &v1beta1.TriggerContext{
+			EventURL:  "https://el.tekton.dev/",
+			EventID:   "a6f1d9e4-1d2b-4c8e-9b3f-1e2d3c4b5a69",
+			TriggerID: "namespaces/tekton/triggers/github",
+		},
+	}
+	r, err := NewCelResolver()
+	assert.Nil(t, err)
+	val, err := r.ValueOf(context.TODO(), req, "requestURL")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://el.tekton.dev/", val)
+	val, err = r.ValueOf(context.TODO(), req, "context.event_id")
+	assert.Nil(t, err)
+	assert.Equal(t, "a6f1d9e4-1d2b-4c8e-9b3f-1e2d3c4b5a69", val)
+	val, err = r.ValueOf(context.TODO(), &Metadata{}, "context.trigger_id")
+	assert.Nil(t, err)
+	assert.Equal(t, "", val)
+}
+
+func TestCelResolver_ValueOf_InvalidPosition(t *testing.T) {
+	r, err := NewCelResolver()
+	assert.Nil(t, err)
+	_, err = r.ValueOf(context.TODO(), &Metadata{}, `body.ref == "main" && bdy.action == "opened"`)
+	var e *ExprInvalidError
+	assert.ErrorAs(t, err, &e)
+	assert.Equal(t, 1, e.Line)
+	assert.Equal(t, 22, e.Column)
+}
+
+func TestCelResolver_ValueOf_Cache(t *testing.T) {
+	r, err := NewCachedCelResolver(2)
+	assert.Nil(t, err)
+	hits := testutil.ToFloat64(programCacheRequests.WithLabelValues(programCacheResultHit))
+	misses := testutil.ToFloat64(programCacheRequests.WithLabelValues(programCacheResultMiss))
+	for _, body := range []map[string]interface{}{{"ref": "main"}, {"ref": "dev"}} {
+		val, err := r.ValueOf(context.TODO(), &Metadata{Body: body}, "body.ref")
+		assert.Nil(t, err)
+		assert.Equal(t, body["ref"], val)
+		val, err = r.SafeValueOf(context.TODO(), &Metadata{Body: body}, "main")
+		assert.Nil(t, err)
+		assert.Equal(t, "main", val)
+	}
+	assert.Equal(t, hits+2, testutil.ToFloat64(programCacheRequests.WithLabelValues(programCacheResultHit)))
+	assert.Equal(t, misses+2, testutil.ToFloat64(programCacheRequests.WithLabelValues(programCacheResultMiss)))
+}
+
+func TestCelResolver_ValueOf_Concurrent(t *testing.T) {
+	r, err := NewCachedCelResolver(4)
+	assert.Nil(t, err)
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			expr := fmt.Sprintf("body.n + %d", i%8)
+			val, err := r.ValueOf(context.TODO(), &Metadata{Body: map[string]interface{}{"n": int64(i)}}, expr)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(i+i%8), val)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// webhookExprs are values of a realistic config, which are resolved for every pipeline of a webhook.
+var webhookExprs = []string{
+	`"action" in body && body.action in ["opened", "synchronize", "reopened"]`,
+	"body.repository.owner.login",
+	"body.repository.name",
+	`"pull_request" in body ? body.pull_request.head.sha : body.head_commit.id`,
+	`https://tekton.dev/#/namespaces/{{ .Namespace }}/pipelineruns/{{ index .Labels "tekton.dev/pipelineRun" }}`,
+	`{{ index .Labels "tekton.dev/pipelineTask" }}`,
+	"body.repository.clone_url",
+	"pipeline-1",
+	"main",
+}
+
+func benchmarkWebhook(b *testing.B, r Resolver) {
+	meta := &Metadata{
+		Body: map[string]interface{}{
+			"action": "opened",
+			"repository": map[string]interface{}{
+				"name":      "tekton-toolbox",
+				"clone_url": "https://github.com/ElementalCognition/tekton-toolbox.git",
+				"owner":     map[string]interface{}{"login": "ElementalCognition"},
+			},
+			"pull_request": map[string]interface{}{
+				"head": map[string]interface{}{"sha": "6dcb09b5b57875f334f61aebed695e2e4193db5e"},
+			},
+		},
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// A webhook triggers a few pipelines, and every value is resolved for each of them.
+		for p := 0; p < 4; p++ {
+			for _, expr := range webhookExprs {
+				if _, err := r.SafeValueOf(context.TODO(), meta, expr); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkCelResolver_Webhook_Uncached(b *testing.B) {
+	r, err := NewCachedCelResolver(0)
+	assert.Nil(b, err)
+	benchmarkWebhook(b, r)
+}
+
+func BenchmarkCelResolver_Webhook_Cached(b *testing.B) {
+	r, err := NewCelResolver()
+	assert.Nil(b, err)
+	benchmarkWebhook(b, r)
+}
@@ -0,0 +1,190 @@
+package pipelineresolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var explicitDelims = []struct {
+	open, close string
+}{
+	{"${{", "}}"},
+	{"$(cel:", ")"},
+}
+
+// templatePart is either a literal, or an expression which starts at offset of a value.
+type templatePart struct {
+	text   string
+	expr   bool
+	offset int
+}
+
+// scanExpr returns the length of an expression at the start of s, which ends with close outside of string literals
+// and brackets, eg. `size(body.commits) > 0)`.
+func scanExpr(s, close string) (int, bool) {
+	var depth int
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case depth == 0 && strings.HasPrefix(s[i:], close):
+			return i, true
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		}
+	}
+	return 0, false
+}
+
+func parseTemplate(val string) ([]templatePart, error) {
+	var parts []templatePart
+	start := 0
+	for i := 0; i < len(val); i++ {
+		for _, d := range explicitDelims {
+			if !strings.HasPrefix(val[i:], d.open) {
+				continue
+			}
+			exprStart := i + len(d.open)
+			n, ok := scanExpr(val[exprStart:], d.close)
+			if !ok {
+				return nil, fmt.Errorf("expression at %d is not closed with `%s`", i, d.close)
+			}
+			if start < i {
+				parts = append(parts, templatePart{text: val[start:i]})
+			}
+			expr := val[exprStart : exprStart+n]
+			trimmed := strings.TrimLeft(expr, " \t\n")
+			parts = append(parts, templatePart{
+				text:   strings.TrimSpace(expr),
+				expr:   true,
+				offset: exprStart + len(expr) - len(trimmed),
+			})
+			start = exprStart + n + len(d.close)
+			i = start - 1
+			break
+		}
+	}
+	if start < len(val) {
+		parts = append(parts, templatePart{text: val[start:]})
+	}
+	return parts, nil
+}
+
+func hasExpr(parts []templatePart) bool {
+	for _, p := range parts {
+		if p.expr {
+			return true
+		}
+	}
+	return false
+}
+
+// shiftExprError makes a position of an issue relative to the whole value instead of the expression in it. A copy is
+// returned, since errors may be cached.
+func shiftExprError(err error, val string, offset int) error {
+	var e *ExprInvalidError
+	if !errors.As(err, &e) || e.Line == 0 {
+		return err
+	}
+	before := val[:offset]
+	shifted := *e
+	shifted.Line += strings.Count(before, "\n")
+	if e.Line == 1 {
+		shifted.Column += len(before) - strings.LastIndex(before, "\n") - 1
+	}
+	return &shifted
+}
+
+// ExplicitResolver resolves only expressions written as `${{ expr }}` or `$(cel: expr)`, and everything else is a
+// literal, so a typo in an expression is an error instead of a literal value. A value with a single expression
+// resolves to its value as is, otherwise expressions are interpolated.
+type ExplicitResolver struct {
+	resolver Resolver
+}
+
+var _ Resolver = (*ExplicitResolver)(nil)
+var _ Referrer = (*ExplicitResolver)(nil)
+
+func (r *ExplicitResolver) resolve(ctx context.Context, meta *Metadata, val string, parts []templatePart) (interface{}, error) {
+	if len(parts) == 1 {
+		v, err := r.resolver.ValueOf(ctx, meta, parts[0].text)
+		if err != nil {
+			return nil, shiftExprError(err, val, parts[0].offset)
+		}
+		return v, nil
+	}
+	var b strings.Builder
+	for _, p := range parts {
+		if !p.expr {
+			b.WriteString(p.text)
+			continue
+		}
+		v, err := r.resolver.ValueOf(ctx, meta, p.text)
+		if err != nil {
+			return nil, shiftExprError(err, val, p.offset)
+		}
+		b.WriteString(fmt.Sprint(v))
+	}
+	return b.String(), nil
+}
+
+// ValueOf resolves val as an expression, unless it is written explicitly, since some values, eg. filters, are
+// always expressions.
+func (r *ExplicitResolver) ValueOf(ctx context.Context, meta *Metadata, val string) (interface{}, error) {
+	parts, err := parseTemplate(val)
+	if err != nil {
+		return nil, &ExprInvalidError{Err: err}
+	}
+	if !hasExpr(parts) {
+		return r.resolver.ValueOf(ctx, meta, val)
+	}
+	return r.resolve(ctx, meta, val, parts)
+}
+
+func (r *ExplicitResolver) SafeValueOf(ctx context.Context, meta *Metadata, val string) (interface{}, error) {
+	parts, err := parseTemplate(val)
+	if err != nil {
+		return nil, &ExprInvalidError{Err: err}
+	}
+	if !hasExpr(parts) {
+		return val, nil
+	}
+	return r.resolve(ctx, meta, val, parts)
+}
+
+// Refers reports whether an expression of val refers to name, or val itself if it has no expressions, since ValueOf
+// resolves it, eg. a filter. Every expression refers to name if the wrapped resolver is not a Referrer.
+func (r *ExplicitResolver) Refers(val, name string) bool {
+	parts, err := parseTemplate(val)
+	if err != nil {
+		return false
+	}
+	ref, ok := r.resolver.(Referrer)
+	if !hasExpr(parts) {
+		return !ok || ref.Refers(val, name)
+	}
+	for _, p := range parts {
+		if p.expr && (!ok || ref.Refers(p.text, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func NewExplicitResolver(resolver Resolver) Resolver {
+	return &ExplicitResolver{
+		resolver: resolver,
+	}
+}
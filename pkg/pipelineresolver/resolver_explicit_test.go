@@ -0,0 +1,65 @@
+package pipelineresolver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplicitResolver_SafeValueOf(t *testing.T) {
+	meta := &Metadata{
+		Body: map[string]interface{}{
+			"ref":     "refs/heads/main",
+			"number":  int64(42),
+			"commits": []interface{}{"a", "b"},
+		},
+	}
+	cr, err := NewCelResolver()
+	assert.Nil(t, err)
+	r := NewExplicitResolver(cr)
+	tests := []struct {
+		val      string
+		expected interface{}
+	}{
+		{"body.ref", "body.ref"},
+		{"main", "main"},
+		{"${{ body.ref }}", "refs/heads/main"},
+		{"$(cel: body.ref)", "refs/heads/main"},
+		{"${{body.number}}", int64(42)},
+		{"$(cel: size(body.commits) > 1)", true},
+		{"pr-${{ body.number }}-$(cel: body.ref.split('/')[2])", "pr-42-main"},
+		{`${{ {"a": {"b": "}}"}}.a.b }}`, "}}"},
+		{"$(params.foo)", "$(params.foo)"},
+	}
+	for _, tt := range tests {
+		val, err := r.SafeValueOf(context.TODO(), meta, tt.val)
+		assert.Nil(t, err, tt.val)
+		assert.Equal(t, tt.expected, val, tt.val)
+	}
+}
+
+func TestExplicitResolver_SafeValueOf_Invalid(t *testing.T) {
+	cr, err := NewCelResolver()
+	assert.Nil(t, err)
+	r := NewExplicitResolver(cr)
+	_, err = r.SafeValueOf(context.TODO(), &Metadata{}, "refs/${{ bdy.ref }}")
+	var e *ExprInvalidError
+	assert.ErrorAs(t, err, &e)
+	assert.Equal(t, 1, e.Line)
+	assert.Equal(t, 9, e.Column)
+	_, err = r.SafeValueOf(context.TODO(), &Metadata{}, "${{ body.ref")
+	assert.ErrorAs(t, err, &e)
+}
+
+func TestExplicitResolver_ValueOf(t *testing.T) {
+	cr, err := NewCelResolver()
+	assert.Nil(t, err)
+	r := NewExplicitResolver(cr)
+	meta := &Metadata{Body: map[string]interface{}{"ref": "refs/heads/main"}}
+	for _, val := range []string{`body.ref == "refs/heads/main"`, `${{ body.ref == "refs/heads/main" }}`} {
+		v, err := r.ValueOf(context.TODO(), meta, val)
+		assert.Nil(t, err)
+		assert.Equal(t, true, v)
+	}
+}
@@ -0,0 +1,140 @@
+package pipelineresolver
+
+import (
+	"context"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	sprig "github.com/go-task/slim-sprig"
+)
+
+// TemplateResolver resolves values as Go templates, eg. `{{ .body.repository.name }}`, with the same variables as
+// CelResolver and sprig functions, except ones which depend on the environment or time.
+type TemplateResolver struct {
+	funcs template.FuncMap
+}
+
+var _ Resolver = (*TemplateResolver)(nil)
+var _ Referrer = (*TemplateResolver)(nil)
+
+func (r *TemplateResolver) parse(val string) (*template.Template, error) {
+	t, err := template.New("value").
+		Funcs(r.funcs).
+		Option("missingkey=error").
+		Parse(val)
+	if err != nil {
+		return nil, &ExprInvalidError{Err: err}
+	}
+	return t, nil
+}
+
+// ValueOf returns an output of a template, and `true` or `false` as a boolean, so a template can be used as a filter.
+func (r *TemplateResolver) ValueOf(_ context.Context, meta *Metadata, val string) (interface{}, error) {
+	t, err := r.parse(val)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	err = t.Execute(&b, map[string]interface{}{
+		"body":         meta.Body,
+		"header":       meta.Header,
+		"extensions":   meta.Extensions,
+		"params":       meta.Params,
+		"changedFiles": meta.ChangedFiles,
+		"requestURL":   meta.RequestURL,
+		"context":      triggerContext(meta.TriggerContext),
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch s := b.String(); s {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	default:
+		return s, nil
+	}
+}
+
+// SafeValueOf uses a value without actions, or which is not a valid template, as is.
+func (r *TemplateResolver) SafeValueOf(ctx context.Context, meta *Metadata, val string) (interface{}, error) {
+	if !strings.Contains(val, "{{") {
+		return val, nil
+	}
+	i, err := r.ValueOf(ctx, meta, val)
+	if err != nil {
+		if _, ok := err.(*ExprInvalidError); ok {
+			return val, nil
+		}
+		return nil, err
+	}
+	return i, nil
+}
+
+// Refers reports whether val is a template with a field or a variable name, eg. `.changedFiles` or `$.changedFiles`.
+func (r *TemplateResolver) Refers(val, name string) bool {
+	if !strings.Contains(val, "{{") {
+		return false
+	}
+	t, err := r.parse(val)
+	if err != nil {
+		return false
+	}
+	return refersNode(t.Root, name)
+}
+
+func refersNode(n parse.Node, name string) bool {
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, c := range n.Nodes {
+			if refersNode(c, name) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return refersNode(n.Pipe, name)
+	case *parse.TemplateNode:
+		return n.Pipe != nil && refersNode(n.Pipe, name)
+	case *parse.IfNode:
+		return refersNode(&n.BranchNode, name)
+	case *parse.RangeNode:
+		return refersNode(&n.BranchNode, name)
+	case *parse.WithNode:
+		return refersNode(&n.BranchNode, name)
+	case *parse.BranchNode:
+		return refersNode(n.Pipe, name) || refersNode(n.List, name) || refersNode(n.ElseList, name)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, c := range n.Cmds {
+			if refersNode(c, name) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			if refersNode(a, name) {
+				return true
+			}
+		}
+	case *parse.ChainNode:
+		return refersNode(n.Node, name)
+	case *parse.FieldNode:
+		return n.Ident[0] == name
+	case *parse.VariableNode:
+		return len(n.Ident) > 1 && n.Ident[0] == "$" && n.Ident[1] == name
+	}
+	return false
+}
+
+func NewTemplateResolver() Resolver {
+	return &TemplateResolver{
+		funcs: sprig.HermeticTxtFuncMap(),
+	}
+}
@@ -0,0 +1,60 @@
+package pipelineresolver
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateResolver_ValueOf(t *testing.T) {
+	header := http.Header{}
+	header.Add("X-Github-Event", "push")
+	meta := &Metadata{
+		Header: header,
+		Body: map[string]interface{}{
+			"ref":        "refs/heads/main",
+			"repository": map[string]interface{}{"name": "tekton-toolbox"},
+		},
+		ChangedFiles: []string{"go.mod", "docs/index.md"},
+	}
+	tests := []struct {
+		val  string
+		want interface{}
+	}{
+		{"{{ .body.repository.name }}", "tekton-toolbox"},
+		{"pr-{{ .body.ref | trimPrefix \"refs/heads/\" }}", "pr-main"},
+		{"{{ index .header \"X-Github-Event\" 0 }}", "push"},
+		{"{{ len .changedFiles }}", "2"},
+		{"{{ eq .body.ref \"refs/heads/main\" }}", true},
+		{"{{ has \"go.sum\" .changedFiles }}", false},
+		{"body.ref", "body.ref"},
+	}
+	r := NewTemplateResolver()
+	for _, tt := range tests {
+		t.Run(tt.val, func(t *testing.T) {
+			val, err := r.ValueOf(context.TODO(), meta, tt.val)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, val)
+		})
+	}
+}
+
+func TestTemplateResolver_ValueOf_MissingKey(t *testing.T) {
+	r := NewTemplateResolver()
+	_, err := r.ValueOf(context.TODO(), &Metadata{Body: map[string]interface{}{}}, "{{ .body.ref }}")
+	assert.ErrorContains(t, err, "map has no entry for key")
+}
+
+func TestTemplateResolver_SafeValueOf(t *testing.T) {
+	r := NewTemplateResolver()
+	val, err := r.SafeValueOf(context.TODO(), &Metadata{}, "{{ .body.ref")
+	assert.Nil(t, err)
+	assert.Equal(t, "{{ .body.ref", val)
+	val, err = r.SafeValueOf(context.TODO(), &Metadata{}, "true")
+	assert.Nil(t, err)
+	assert.Equal(t, "true", val)
+	_, err = r.ValueOf(context.TODO(), &Metadata{}, "{{ .body.ref")
+	assert.IsType(t, &ExprInvalidError{}, err)
+}
@@ -0,0 +1,61 @@
+package pipelinerun
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PathElem is a field of a FieldError path, or an item of a sequence if Key is empty.
+type PathElem struct {
+	Key   string
+	Index int
+	// Name is a name of an item, which identifies it better than Index, since items are merged by name.
+	Name string
+}
+
+// FieldError is an error of a field of a PipelineRun, eg. `timeouts.pipeline`, so configs can locate it in their
+// source.
+type FieldError struct {
+	Err  error
+	Path []PathElem
+}
+
+func (e *FieldError) Error() string {
+	var b strings.Builder
+	for _, p := range e.Path {
+		switch {
+		case p.Key != "":
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(p.Key)
+		case p.Name != "":
+			fmt.Fprintf(&b, "[%s]", p.Name)
+		default:
+			fmt.Fprintf(&b, "[%d]", p.Index)
+		}
+	}
+	return fmt.Sprintf("%s: %v", b.String(), e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func withPath(err error, elems ...PathElem) error {
+	var e *FieldError
+	if errors.As(err, &e) {
+		e.Path = append(elems, e.Path...)
+		return err
+	}
+	return &FieldError{Err: err, Path: elems}
+}
+
+func fieldError(key string, err error) error {
+	return withPath(err, PathElem{Key: key})
+}
+
+func itemError(key string, index int, name string, err error) error {
+	return withPath(err, PathElem{Key: key}, PathElem{Index: index, Name: name})
+}
@@ -14,13 +14,25 @@ type Metadata struct {
 var _ pipelineresolver.Reconciler = (*Metadata)(nil)
 
 func (m *Metadata) Reconcile(ctx context.Context, meta *pipelineresolver.Metadata) error {
-	err := MetadataMap(m.Labels).Reconcile(ctx, meta)
+	r, err := pipelineresolver.FromContext(ctx)
 	if err != nil {
 		return err
 	}
+	err = resolveString(ctx, r, meta, "namespace", &m.Namespace)
+	if err != nil {
+		return fieldError("metadata", err)
+	}
+	err = resolveString(ctx, r, meta, "generateName", &m.GenerateName)
+	if err != nil {
+		return fieldError("metadata", err)
+	}
+	err = MetadataMap(m.Labels).Reconcile(ctx, meta)
+	if err != nil {
+		return fieldError("metadata", fieldError("labels", err))
+	}
 	err = MetadataMap(m.Annotations).Reconcile(ctx, meta)
 	if err != nil {
-		return err
+		return fieldError("metadata", fieldError("annotations", err))
 	}
 	return nil
 }
@@ -37,7 +49,7 @@ func (m MetadataMap) Reconcile(ctx context.Context, meta *pipelineresolver.Metad
 	for k, v := range m {
 		i, err := r.SafeValueOf(ctx, meta, v)
 		if err != nil {
-			return err
+			return fieldError(k, err)
 		}
 		m[k] = fmt.Sprint(i)
 	}
@@ -13,7 +13,54 @@ type Param struct {
 
 var _ pipelineresolver.Reconciler = (*Param)(nil)
 
+// stringValue formats a scalar value, and fails for a list or a map, which is not a valid string, array item or
+// object value.
+func stringValue(i interface{}) (string, error) {
+	switch i.(type) {
+	case []interface{}:
+		return "", fmt.Errorf("expected a string, got a list")
+	case map[string]interface{}:
+		return "", fmt.Errorf("expected a string, got a map")
+	}
+	return fmt.Sprint(i), nil
+}
+
+func arrayValue(l []interface{}) ([]string, error) {
+	vals := make([]string, 0, len(l))
+	for _, i := range l {
+		s, err := stringValue(i)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, s)
+	}
+	return vals, nil
+}
+
+func (p *Param) resolve(ctx context.Context, r pipelineresolver.Resolver, metadata *pipelineresolver.Metadata, index int, val string) (string, error) {
+	i, err := r.SafeValueOf(ctx, metadata, val)
+	if err != nil {
+		return "", itemError("params", index, p.Name, err)
+	}
+	s, err := stringValue(i)
+	if err != nil {
+		return "", p.typeError(index, p.Value.Type, err)
+	}
+	return s, nil
+}
+
+// typeError returns err as a FieldError of the param, which is item index of params.
+func (p *Param) typeError(index int, t v1.ParamType, err error) error {
+	return itemError("params", index, p.Name, fmt.Errorf("of type %s: %w", t, err))
+}
+
+// Reconcile resolves a value of a string param, which becomes an array param if it resolves to a list, every item of
+// an array param, or every key of an object param.
 func (p *Param) Reconcile(ctx context.Context, metadata *pipelineresolver.Metadata) error {
+	return p.reconcile(ctx, metadata, 0)
+}
+
+func (p *Param) reconcile(ctx context.Context, metadata *pipelineresolver.Metadata, index int) error {
 	r, err := pipelineresolver.FromContext(ctx)
 	if err != nil {
 		return err
@@ -22,16 +69,36 @@ func (p *Param) Reconcile(ctx context.Context, metadata *pipelineresolver.Metada
 	case v1.ParamTypeString:
 		i, err := r.SafeValueOf(ctx, metadata, p.Value.StringVal)
 		if err != nil {
-			return err
+			return itemError("params", index, p.Name, err)
+		}
+		if l, ok := i.([]interface{}); ok {
+			vals, err := arrayValue(l)
+			if err != nil {
+				return p.typeError(index, v1.ParamTypeArray, err)
+			}
+			p.Value = v1.ParamValue{Type: v1.ParamTypeArray, ArrayVal: vals}
+			return nil
+		}
+		s, err := stringValue(i)
+		if err != nil {
+			return p.typeError(index, p.Value.Type, err)
 		}
-		p.Value.StringVal = fmt.Sprint(i)
+		p.Value.StringVal = s
 	case v1.ParamTypeArray:
 		for k, v := range p.Value.ArrayVal {
-			i, err := r.SafeValueOf(ctx, metadata, v)
+			s, err := p.resolve(ctx, r, metadata, index, v)
+			if err != nil {
+				return err
+			}
+			p.Value.ArrayVal[k] = s
+		}
+	case v1.ParamTypeObject:
+		for k, v := range p.Value.ObjectVal {
+			s, err := p.resolve(ctx, r, metadata, index, v)
 			if err != nil {
 				return err
 			}
-			p.Value.ArrayVal[k] = fmt.Sprint(i)
+			p.Value.ObjectVal[k] = s
 		}
 	}
 	return nil
@@ -42,8 +109,8 @@ type ParamSlice []*Param
 var _ pipelineresolver.Reconciler = (*ParamSlice)(nil)
 
 func (s *ParamSlice) Reconcile(ctx context.Context, meta *pipelineresolver.Metadata) error {
-	for _, p := range *s {
-		if err := p.Reconcile(ctx, meta); err != nil {
+	for i, p := range *s {
+		if err := p.reconcile(ctx, meta, i); err != nil {
 			return err
 		}
 	}
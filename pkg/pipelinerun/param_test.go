@@ -106,3 +106,108 @@ func TestParam_Reconcile_ArrayDefault(t *testing.T) {
 		"bar",
 	}, p.Value.ArrayVal)
 }
+
+func TestParam_Reconcile_StringList(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	ctx := pipelineresolver.WithResolver(context.TODO(), r)
+	p := &Param{
+		Param: v1.Param{
+			Name: "files",
+			Value: v1.ParamValue{
+				Type:      v1.ParamTypeString,
+				StringVal: "changedFiles.filter(f, f.endsWith('.go'))",
+			},
+		},
+	}
+	req := &pipelineresolver.Metadata{
+		ChangedFiles: []string{"main.go", "README.md", "pkg/foo.go"},
+	}
+	err = p.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, v1.ParamTypeArray, p.Value.Type)
+	assert.Equal(t, []string{"main.go", "pkg/foo.go"}, p.Value.ArrayVal)
+	assert.Empty(t, p.Value.StringVal)
+}
+
+func TestParam_Reconcile_Object(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	ctx := pipelineresolver.WithResolver(context.TODO(), r)
+	p := &Param{
+		Param: v1.Param{
+			Value: v1.ParamValue{
+				Type: v1.ParamTypeObject,
+				ObjectVal: map[string]string{
+					"url":    "body.repository.clone_url",
+					"number": "body.number",
+					"branch": "main",
+				},
+			},
+		},
+	}
+	req := &pipelineresolver.Metadata{
+		Body: map[string]interface{}{
+			"number": 42,
+			"repository": map[string]interface{}{
+				"clone_url": "foo",
+			},
+		},
+	}
+	err = p.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"url":    "foo",
+		"number": "42",
+		"branch": "main",
+	}, p.Value.ObjectVal)
+}
+
+func TestParam_Reconcile_TypeMismatch(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	ctx := pipelineresolver.WithResolver(context.TODO(), r)
+	req := &pipelineresolver.Metadata{
+		Body: map[string]interface{}{
+			"repository": map[string]interface{}{
+				"clone_url": "foo",
+			},
+		},
+		ChangedFiles: []string{"main.go"},
+	}
+	tests := []struct {
+		value v1.ParamValue
+		err   string
+	}{
+		{*v1.NewStructuredValues("body.repository"), "params[foo]: of type string: expected a string, got a map"},
+		{*v1.NewStructuredValues("[changedFiles]"), "params[foo]: of type array: expected a string, got a list"},
+		{*v1.NewStructuredValues("foo", "changedFiles"), "params[foo]: of type array: expected a string, got a list"},
+		{*v1.NewObject(map[string]string{"url": "body.repository"}), "params[foo]: of type object: expected a string, got a map"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			p := &Param{Param: v1.Param{Name: "foo", Value: tt.value}}
+			err := p.Reconcile(ctx, req)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestParamSlice_Reconcile_FieldError(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	ctx := pipelineresolver.WithResolver(context.TODO(), r)
+	req := &pipelineresolver.Metadata{
+		Body: map[string]interface{}{"repository": map[string]interface{}{"clone_url": "foo"}},
+	}
+	s := ParamSlice{
+		{Param: v1.Param{Name: "url", Value: *v1.NewStructuredValues("body.repository.clone_url")}},
+		{Param: v1.Param{Name: "repository", Value: *v1.NewStructuredValues("body.repository")}},
+	}
+	err = s.Reconcile(ctx, req)
+	var fe *FieldError
+	if assert.ErrorAs(t, err, &fe) {
+		assert.Equal(t, []PathElem{{Key: "params"}, {Index: 1, Name: "repository"}}, fe.Path)
+	}
+	assert.EqualError(t, err, "params[repository]: of type string: expected a string, got a map")
+}
@@ -16,6 +16,9 @@ type PipelineRun struct {
 	Name     string `json:"name,omitempty"`
 	Metadata `json:"metadata,omitempty"`
 	Params   ParamSlice `json:"params,omitempty"`
+	Timeouts *Timeouts  `json:"timeouts,omitempty"`
+	// Needs are names of pipelines of the same trigger, which have to succeed before this one is created.
+	Needs []string `json:"needs,omitempty"`
 }
 
 type PipelineSlice []PipelineRun
@@ -36,11 +39,31 @@ func (p *PipelineRun) MergeAll(ds ...*PipelineRun) error {
 	return nil
 }
 
+// deepCopy copies maps, slices and pointers which merging may share with a config, so reconciling does not modify it.
+func (p *PipelineRun) deepCopy() {
+	p.ObjectMeta = *p.ObjectMeta.DeepCopy()
+	p.PipelineRunSpec = *p.PipelineRunSpec.DeepCopy()
+	if p.Timeouts != nil {
+		t := *p.Timeouts
+		p.Timeouts = &t
+	}
+	params := make(ParamSlice, 0, len(p.Params))
+	for _, param := range p.Params {
+		params = append(params, &Param{Param: *param.Param.DeepCopy()})
+	}
+	p.Params = params
+}
+
 func (p *PipelineRun) Reconcile(ctx context.Context, meta *pipelineresolver.Metadata) error {
+	p.deepCopy()
 	err := p.Metadata.Reconcile(ctx, meta)
 	if err != nil {
 		return err
 	}
+	err = p.reconcileSpec(ctx, meta)
+	if err != nil {
+		return err
+	}
 	return p.Params.Reconcile(ctx, meta)
 }
 
@@ -50,13 +73,20 @@ func (p *PipelineRun) PipelineRun() (*v1.PipelineRun, error) {
 		name = p.PipelineRef.Name
 	}
 	meta := p.Metadata.DeepCopy()
-	meta.GenerateName = fmt.Sprintf("%s-run-", name)
+	if meta.GenerateName == "" {
+		meta.GenerateName = fmt.Sprintf("%s-run-", name)
+	}
 	var params []v1.Param
 	for _, p := range p.Params {
 		params = append(params, *p.Param.DeepCopy())
 	}
+	timeouts, err := p.Timeouts.TimeoutFields()
+	if err != nil {
+		return nil, err
+	}
 	spec := p.PipelineRunSpec.DeepCopy()
 	spec.Params = params
+	spec.Timeouts = timeouts
 	return &v1.PipelineRun{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "PipelineRun",
@@ -0,0 +1,125 @@
+package pipelinerun
+
+import (
+	"context"
+	"time"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Timeouts are strings instead of durations, so they can be expressions, eg. `params.timeout`.
+type Timeouts struct {
+	Pipeline string `json:"pipeline,omitempty"`
+	Tasks    string `json:"tasks,omitempty"`
+	Finally  string `json:"finally,omitempty"`
+}
+
+func parseDuration(field, val string) (*metav1.Duration, error) {
+	if val == "" {
+		return nil, nil
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return nil, fieldError("timeouts", fieldError(field, err))
+	}
+	return &metav1.Duration{Duration: d}, nil
+}
+
+func (t *Timeouts) TimeoutFields() (*v1.TimeoutFields, error) {
+	if t == nil {
+		return nil, nil
+	}
+	var f v1.TimeoutFields
+	var err error
+	if f.Pipeline, err = parseDuration("pipeline", t.Pipeline); err != nil {
+		return nil, err
+	}
+	if f.Tasks, err = parseDuration("tasks", t.Tasks); err != nil {
+		return nil, err
+	}
+	if f.Finally, err = parseDuration("finally", t.Finally); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// resolveString resolves a non-empty value of field key in place.
+func resolveString(ctx context.Context, r pipelineresolver.Resolver, meta *pipelineresolver.Metadata, key string, val *string) error {
+	if *val == "" {
+		return nil
+	}
+	i, err := r.SafeValueOf(ctx, meta, *val)
+	if err != nil {
+		return fieldError(key, err)
+	}
+	s, err := stringValue(i)
+	if err != nil {
+		return fieldError(key, err)
+	}
+	*val = s
+	return nil
+}
+
+func reconcilePodTemplate(ctx context.Context, meta *pipelineresolver.Metadata, t *pod.PodTemplate) error {
+	if t == nil {
+		return nil
+	}
+	if err := MetadataMap(t.NodeSelector).Reconcile(ctx, meta); err != nil {
+		return fieldError("podTemplate", fieldError("nodeSelector", err))
+	}
+	return nil
+}
+
+// reconcileSpec resolves fields of a spec which route a run, eg. to a namespace or a service account.
+func (p *PipelineRun) reconcileSpec(ctx context.Context, meta *pipelineresolver.Metadata) error {
+	r, err := pipelineresolver.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if p.PipelineRef != nil {
+		if err := resolveString(ctx, r, meta, "name", &p.PipelineRef.Name); err != nil {
+			return fieldError("pipelineRef", err)
+		}
+	}
+	if p.Timeouts != nil {
+		for _, f := range []struct {
+			name string
+			val  *string
+		}{
+			{"pipeline", &p.Timeouts.Pipeline},
+			{"tasks", &p.Timeouts.Tasks},
+			{"finally", &p.Timeouts.Finally},
+		} {
+			if err := resolveString(ctx, r, meta, f.name, f.val); err != nil {
+				return fieldError("timeouts", err)
+			}
+		}
+	}
+	err = resolveString(ctx, r, meta, "serviceAccountName", &p.TaskRunTemplate.ServiceAccountName)
+	if err != nil {
+		return fieldError("taskRunTemplate", err)
+	}
+	if err := reconcilePodTemplate(ctx, meta, p.TaskRunTemplate.PodTemplate); err != nil {
+		return fieldError("taskRunTemplate", err)
+	}
+	for i := range p.TaskRunSpecs {
+		// Specs are identified by pipelineTaskName rather than name, so they are located by index.
+		s := &p.TaskRunSpecs[i]
+		if err := resolveString(ctx, r, meta, "serviceAccountName", &s.ServiceAccountName); err != nil {
+			return itemError("taskRunSpecs", i, "", err)
+		}
+		if err := reconcilePodTemplate(ctx, meta, s.PodTemplate); err != nil {
+			return itemError("taskRunSpecs", i, "", err)
+		}
+	}
+	for i := range p.Workspaces {
+		w := &p.Workspaces[i]
+		if err := resolveString(ctx, r, meta, "subPath", &w.SubPath); err != nil {
+			return itemError("workspaces", i, w.Name, err)
+		}
+	}
+	return nil
+}
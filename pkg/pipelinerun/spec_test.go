@@ -0,0 +1,130 @@
+package pipelinerun
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/stretchr/testify/assert"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestPipelineRun_Reconcile_Spec(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	ctx := pipelineresolver.WithResolver(context.TODO(), r)
+	var src PipelineRun
+	err = yaml.Unmarshal([]byte(`
+name: build
+metadata:
+  namespace: "'ci-' + body.repository.name"
+  generateName: "body.repository.name + '-'"
+pipelineRef:
+  name: "'build-' + body.language"
+timeouts:
+  pipeline: "isPullRequest() ? '30m' : '1h'"
+  tasks: 20m
+taskRunTemplate:
+  serviceAccountName: "body.repository.name + '-ci'"
+  podTemplate:
+    nodeSelector:
+      pool: body.language
+taskRunSpecs:
+  - pipelineTaskName: deploy
+    serviceAccountName: "body.repository.name + '-deploy'"
+    podTemplate:
+      nodeSelector:
+        pool: "'deploy'"
+workspaces:
+  - name: cache
+    subPath: "'cache/' + body.repository.name"
+`), &src)
+	assert.Nil(t, err)
+	meta := &pipelineresolver.Metadata{
+		Body: map[string]interface{}{
+			"language":   "go",
+			"repository": map[string]interface{}{"name": "toolbox"},
+		},
+	}
+	p := &PipelineRun{}
+	err = p.MergeAll(&src)
+	assert.Nil(t, err)
+	err = p.Reconcile(ctx, meta)
+	assert.Nil(t, err)
+	pr, err := p.PipelineRun()
+	assert.Nil(t, err)
+	assert.Equal(t, "ci-toolbox", pr.Namespace)
+	assert.Equal(t, "toolbox-", pr.GenerateName)
+	assert.Equal(t, "build-go", pr.Spec.PipelineRef.Name)
+	assert.Equal(t, &v1.TimeoutFields{
+		Pipeline: &metav1.Duration{Duration: time.Hour},
+		Tasks:    &metav1.Duration{Duration: 20 * time.Minute},
+	}, pr.Spec.Timeouts)
+	assert.Equal(t, v1.PipelineTaskRunTemplate{
+		ServiceAccountName: "toolbox-ci",
+		PodTemplate:        &pod.PodTemplate{NodeSelector: map[string]string{"pool": "go"}},
+	}, pr.Spec.TaskRunTemplate)
+	assert.Equal(t, "toolbox-deploy", pr.Spec.TaskRunSpecs[0].ServiceAccountName)
+	assert.Equal(t, map[string]string{"pool": "deploy"}, pr.Spec.TaskRunSpecs[0].PodTemplate.NodeSelector)
+	assert.Equal(t, "cache/toolbox", pr.Spec.Workspaces[0].SubPath)
+
+	// A merged config is not modified, so it can be reconciled again.
+	assert.Equal(t, "'build-' + body.language", src.PipelineRef.Name)
+	assert.Equal(t, "body.language", src.TaskRunTemplate.PodTemplate.NodeSelector["pool"])
+	assert.Equal(t, "isPullRequest() ? '30m' : '1h'", src.Timeouts.Pipeline)
+}
+
+func TestPipelineRun_PipelineRun_InvalidTimeout(t *testing.T) {
+	p := &PipelineRun{Name: "build", Timeouts: &Timeouts{Tasks: "body.timeout"}}
+	_, err := p.PipelineRun()
+	assert.ErrorContains(t, err, "timeouts.tasks")
+}
+
+func TestPipelineRun_Reconcile_FieldError(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	ctx := pipelineresolver.WithResolver(context.TODO(), r)
+	meta := &pipelineresolver.Metadata{
+		Body: map[string]interface{}{"repository": map[string]interface{}{"name": "toolbox"}},
+	}
+	tests := []struct {
+		pipeline PipelineRun
+		path     []PathElem
+	}{
+		{
+			PipelineRun{Metadata: Metadata{metav1.ObjectMeta{Namespace: "body.repository"}}},
+			[]PathElem{{Key: "metadata"}, {Key: "namespace"}},
+		},
+		{
+			PipelineRun{Timeouts: &Timeouts{Pipeline: "body.repository"}},
+			[]PathElem{{Key: "timeouts"}, {Key: "pipeline"}},
+		},
+		{
+			PipelineRun{PipelineRunSpec: v1.PipelineRunSpec{
+				TaskRunSpecs: []v1.PipelineTaskRunSpec{{PipelineTaskName: "deploy"}, {ServiceAccountName: "body.repository"}},
+			}},
+			[]PathElem{{Key: "taskRunSpecs"}, {Index: 1}, {Key: "serviceAccountName"}},
+		},
+		{
+			PipelineRun{PipelineRunSpec: v1.PipelineRunSpec{
+				Workspaces: []v1.WorkspaceBinding{{Name: "cache", SubPath: "body.repository"}},
+			}},
+			[]PathElem{{Key: "workspaces"}, {Name: "cache"}, {Key: "subPath"}},
+		},
+	}
+	for _, tt := range tests {
+		err := tt.pipeline.Reconcile(ctx, meta)
+		var fe *FieldError
+		if assert.ErrorAs(t, err, &fe) {
+			assert.Equal(t, tt.path, fe.Path)
+		}
+	}
+	p := &PipelineRun{PipelineRunSpec: v1.PipelineRunSpec{
+		Workspaces: []v1.WorkspaceBinding{{Name: "cache", SubPath: "body.repository"}},
+	}}
+	assert.EqualError(t, p.Reconcile(ctx, meta), "workspaces[cache].subPath: expected a string, got a map")
+}
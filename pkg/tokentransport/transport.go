@@ -0,0 +1,27 @@
+package tokentransport
+
+import (
+	"net/http"
+)
+
+type transport struct {
+	header string
+	value  string
+	base   http.RoundTripper
+}
+
+var _ http.RoundTripper = (*transport)(nil)
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.Header.Set(t.header, t.value)
+	return t.base.RoundTrip(r)
+}
+
+func NewTransport(header, value string) http.RoundTripper {
+	return &transport{
+		header: header,
+		value:  value,
+		base:   http.DefaultTransport,
+	}
+}
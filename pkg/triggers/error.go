@@ -5,4 +5,5 @@ import "errors"
 var (
 	ErrPipelineConfigNotFound  = errors.New("config does not exist")
 	ErrPipelineConfigMalformed = errors.New("config is malformed")
+	ErrChangedFilesMalformed   = errors.New("changed files are malformed")
 )
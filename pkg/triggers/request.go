@@ -6,6 +6,9 @@ import (
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
 )
 
+// ChangedFilesKey is an extension key of files changed by a webhook event.
+const ChangedFilesKey = "changed-files"
+
 type InterceptorRequest v1beta1.InterceptorRequest
 
 func (tr *InterceptorRequest) CurrentConfig() (*pipelineconfig.Config, error) {
@@ -36,8 +39,37 @@ func (tr *InterceptorRequest) MergeConfig(cfg *pipelineconfig.Config) (*pipeline
 	return c, err
 }
 
+// RequestURL returns the URL of the incoming event, or an empty string if the trigger context is unknown.
+func (tr *InterceptorRequest) RequestURL() string {
+	if tr.Context == nil {
+		return ""
+	}
+	return tr.Context.EventURL
+}
+
 func (tr *InterceptorRequest) UnmarshalBody() (map[string]interface{}, error) {
 	var body map[string]interface{}
 	err := json.Unmarshal([]byte(tr.Body), &body)
 	return body, err
 }
+
+// ChangedFiles returns files changed by a webhook event, or nil when they are unknown.
+func (tr *InterceptorRequest) ChangedFiles() ([]string, error) {
+	v, ok := tr.Extensions[ChangedFilesKey]
+	if !ok {
+		return nil, nil
+	}
+	l, ok := v.([]interface{})
+	if !ok {
+		return nil, ErrChangedFilesMalformed
+	}
+	files := make([]string, 0, len(l))
+	for _, f := range l {
+		s, ok := f.(string)
+		if !ok {
+			return nil, ErrChangedFilesMalformed
+		}
+		files = append(files, s)
+	}
+	return files, nil
+}
@@ -19,3 +19,27 @@ func TestInterceptorRequest_UnmarshalBody(t *testing.T) {
 		"foo": "bar",
 	}, body)
 }
+
+func TestInterceptorRequest_ChangedFiles(t *testing.T) {
+	var tr InterceptorRequest
+	err := json.Unmarshal([]byte(`{"extensions": {"changed-files": ["foo/bar.go"]}}`), &tr)
+	assert.Nil(t, err)
+	files, err := tr.ChangedFiles()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"foo/bar.go"}, files)
+	tr.Extensions = nil
+	files, err = tr.ChangedFiles()
+	assert.Nil(t, err)
+	assert.Nil(t, files)
+	tr.Extensions = map[string]interface{}{ChangedFilesKey: "foo/bar.go"}
+	_, err = tr.ChangedFiles()
+	assert.Equal(t, ErrChangedFilesMalformed, err)
+}
+
+func TestInterceptorRequest_RequestURL(t *testing.T) {
+	var tr InterceptorRequest
+	assert.Equal(t, "", tr.RequestURL())
+	err := json.Unmarshal([]byte(`{"context": {"event_url": "https://el.tekton.dev/", "event_id": "foo"}}`), &tr)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://el.tekton.dev/", tr.RequestURL())
+}
@@ -0,0 +1,134 @@
+package vcspipelineconfig
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	cacheResultHit         = "hit"
+	cacheResultNegativeHit = "negative_hit"
+	cacheResultMiss        = "miss"
+	cacheResultBypass      = "bypass"
+	// noExpiration is used as a TTL of entries when CacheOptions.TTL is not set.
+	noExpiration = 100 * 365 * 24 * time.Hour
+)
+
+var cacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "pipeline_config_cache_requests_total",
+	Help: "Number of config requests by cache result: hit, negative_hit, miss, or bypass for refs which are not a commit SHA.",
+}, []string{"result"})
+
+func init() {
+	prometheus.MustRegister(cacheRequests)
+}
+
+type CacheOptions struct {
+	// Size is the maximum number of cached configs.
+	Size int
+	// TTL is the time to keep a config, zero keeps it until evicted.
+	TTL time.Duration
+}
+
+type cacheKey struct {
+	owner, repo, ref, path string
+}
+
+// cacheEntry holds either a config, or an ErrNotFound error.
+type cacheEntry struct {
+	config []byte
+	err    error
+}
+
+type cachedService struct {
+	service Service
+	cache   *cache.LRUExpireCache
+	ttl     time.Duration
+}
+
+var _ Service = (*cachedService)(nil)
+var _ ParamDefaulter = (*cachedService)(nil)
+var _ ChangedFilesLister = (*cachedService)(nil)
+
+func (s *cachedService) load(e *cacheEntry) (*pipelineconfig.Config, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	// Configs are unmarshalled on every hit, so callers may not modify cached ones.
+	cfg := &pipelineconfig.Config{}
+	err := cfg.UnmarshalJSON(e.config)
+	return cfg, err
+}
+
+func (s *cachedService) Get(ctx context.Context, owner, repo, ref, path string) (*pipelineconfig.Config, error) {
+	if !IsCommitSHA(ref) {
+		cacheRequests.WithLabelValues(cacheResultBypass).Inc()
+		return s.service.Get(ctx, owner, repo, ref, path)
+	}
+	logger := logging.FromContext(ctx)
+	key := cacheKey{owner: owner, repo: repo, ref: ref, path: path}
+	if v, ok := s.cache.Get(key); ok {
+		e := v.(*cacheEntry)
+		if e.err != nil {
+			cacheRequests.WithLabelValues(cacheResultNegativeHit).Inc()
+		} else {
+			cacheRequests.WithLabelValues(cacheResultHit).Inc()
+		}
+		logger.Debugw("Service found cached config",
+			zap.String("owner", owner),
+			zap.String("repository", repo),
+			zap.String("ref", ref),
+			zap.String("path", path),
+		)
+		return s.load(e)
+	}
+	cacheRequests.WithLabelValues(cacheResultMiss).Inc()
+	cfg, err := s.service.Get(ctx, owner, repo, ref, path)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			s.cache.Add(key, &cacheEntry{err: err}, s.ttl)
+		}
+		return nil, err
+	}
+	buf, err := cfg.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Add(key, &cacheEntry{config: buf}, s.ttl)
+	return cfg, nil
+}
+
+func (s *cachedService) DefaultParams() map[string]string {
+	if d, ok := s.service.(ParamDefaulter); ok {
+		return d.DefaultParams()
+	}
+	return nil
+}
+
+func (s *cachedService) ChangedFiles(ctx context.Context, owner, repo string, body map[string]interface{}) ([]string, error) {
+	if l, ok := s.service.(ChangedFilesLister); ok {
+		return l.ChangedFiles(ctx, owner, repo, body)
+	}
+	return nil, nil
+}
+
+// NewCachedService wraps service with an in-memory LRU cache of configs and missing configs. Only refs which are
+// a commit SHA are cached, since branches and tags may move.
+func NewCachedService(service Service, opts CacheOptions) Service {
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = noExpiration
+	}
+	return &cachedService{
+		service: service,
+		cache:   cache.NewLRUExpireCache(opts.Size),
+		ttl:     ttl,
+	}
+}
@@ -0,0 +1,86 @@
+package vcspipelineconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSHA = "0123456789abcdef0123456789abcdef01234567"
+
+type countingService struct {
+	fakeService
+	calls int
+	err   error
+}
+
+func (s *countingService) Get(ctx context.Context, owner, repo, ref, path string) (*pipelineconfig.Config, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &pipelineconfig.Config{Triggers: pipelineconfig.TriggerSlice{{Name: "foo"}}}, nil
+}
+
+func TestCachedService_Get(t *testing.T) {
+	svc := &countingService{}
+	c := NewCachedService(svc, CacheOptions{Size: 10})
+	hits := testutil.ToFloat64(cacheRequests.WithLabelValues(cacheResultHit))
+	for i := 0; i < 2; i++ {
+		cfg, err := c.Get(context.TODO(), "foo", "bar", testSHA, DefaultPath)
+		assert.Nil(t, err)
+		assert.Len(t, cfg.Triggers, 1)
+		cfg.Triggers = nil
+	}
+	assert.Equal(t, 1, svc.calls)
+	assert.Equal(t, hits+1, testutil.ToFloat64(cacheRequests.WithLabelValues(cacheResultHit)))
+	_, err := c.Get(context.TODO(), "foo", "bar", testSHA, ".tekton")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, svc.calls)
+}
+
+func TestCachedService_Get_Branch(t *testing.T) {
+	svc := &countingService{}
+	c := NewCachedService(svc, CacheOptions{Size: 10})
+	for i := 0; i < 2; i++ {
+		_, err := c.Get(context.TODO(), "foo", "bar", "main", DefaultPath)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 2, svc.calls)
+}
+
+func TestCachedService_Get_NotFound(t *testing.T) {
+	svc := &countingService{err: ErrNotFound}
+	c := NewCachedService(svc, CacheOptions{Size: 10})
+	for i := 0; i < 2; i++ {
+		_, err := c.Get(context.TODO(), "foo", "bar", testSHA, DefaultPath)
+		assert.ErrorIs(t, err, ErrNotFound)
+	}
+	assert.Equal(t, 1, svc.calls)
+	svc.err = assert.AnError
+	_, err := c.Get(context.TODO(), "foo", "baz", testSHA, DefaultPath)
+	assert.NotNil(t, err)
+	_, err = c.Get(context.TODO(), "foo", "baz", testSHA, DefaultPath)
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, svc.calls)
+}
+
+func TestCachedService_Get_TTL(t *testing.T) {
+	svc := &countingService{}
+	c := NewCachedService(svc, CacheOptions{Size: 10, TTL: time.Millisecond})
+	_, err := c.Get(context.TODO(), "foo", "bar", testSHA, DefaultPath)
+	assert.Nil(t, err)
+	time.Sleep(5 * time.Millisecond)
+	_, err = c.Get(context.TODO(), "foo", "bar", testSHA, DefaultPath)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, svc.calls)
+}
+
+func TestCachedService_DefaultParams(t *testing.T) {
+	c := NewCachedService(&fakeService{}, CacheOptions{Size: 10})
+	assert.Equal(t, (&fakeService{}).DefaultParams(), c.(ParamDefaulter).DefaultParams())
+}
@@ -0,0 +1,64 @@
+package vcspipelineconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+)
+
+const (
+	DefaultPath = ".tekton.yaml"
+	configExt   = ".yaml"
+)
+
+// ContentFunc returns either the content of a file at path, or names of files in a directory at path.
+type ContentFunc func(ctx context.Context, path string) (file []byte, dir []string, err error)
+
+// unmarshalConfig prefixes a located diagnostic with p, eg. `.tekton/build.yaml:3:13: triggers[0].name`.
+func unmarshalConfig(p string, buf []byte) (*pipelineconfig.Config, error) {
+	cfg := &pipelineconfig.Config{}
+	err := cfg.UnmarshalYAML(buf)
+	var d *pipelineconfig.Diagnostic
+	if errors.As(err, &d) && d.Line > 0 {
+		return nil, fmt.Errorf("%s:%w", p, err)
+	}
+	return cfg, err
+}
+
+// ReadConfig reads config from a file at p, or merges all `*.yaml` files of a directory at p in lexical order.
+func ReadConfig(ctx context.Context, p string, read ContentFunc) (*pipelineconfig.Config, error) {
+	file, dir, err := read(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if dir == nil {
+		return unmarshalConfig(p, file)
+	}
+	names := make([]string, 0, len(dir))
+	for _, name := range dir {
+		if path.Ext(name) == configExt {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	cfg := &pipelineconfig.Config{}
+	for _, name := range names {
+		buf, _, err := read(ctx, path.Join(p, name))
+		if err != nil {
+			return nil, err
+		}
+		c, err := unmarshalConfig(path.Join(p, name), buf)
+		if err != nil {
+			return nil, err
+		}
+		err = cfg.Merge(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,18 @@
+package vcspipelineconfig
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is wrapped by errors of services when a config file or directory does not exist at ref.
+var ErrNotFound = errors.New("config does not exist")
+
+// NotFound wraps err of a path which is not found with ErrNotFound, if resolve finds ref. APIs respond with not found
+// for a missing ref or repository, or one which is not accessible anymore, too, which must fail rather than skip runs.
+func NotFound(err error, resolve func() error) error {
+	if rerr := resolve(); rerr != nil {
+		return fmt.Errorf("%w: unable to resolve ref: %w", err, rerr)
+	}
+	return fmt.Errorf("%w: %w", ErrNotFound, err)
+}
@@ -0,0 +1,35 @@
+package vcspipelineconfig
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+)
+
+// NewIncludeLoader returns a loader of includes like `github://owner/repo@ref/path`. A ref may not contain `/`,
+// and path defaults to `.tekton.yaml`. Repositories of other owners than owners are rejected, since the service
+// may read any repository its credentials can.
+func NewIncludeLoader(service Service, owners []string) pipelineconfig.IncludeLoader {
+	return func(ctx context.Context, u *url.URL) (*pipelineconfig.Config, error) {
+		repoRef, path, _ := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+		repo, ref, ok := strings.Cut(repoRef, "@")
+		if u.Host == "" || repo == "" || !ok || ref == "" {
+			return nil, fmt.Errorf("include %s must look like %s://owner/repo@ref/path", u, u.Scheme)
+		}
+		allowed := slices.ContainsFunc(owners, func(owner string) bool {
+			return strings.EqualFold(owner, u.Host)
+		})
+		if !allowed {
+			return nil, fmt.Errorf("%w: owner %s is not one of [%s]", pipelineconfig.ErrIncludeNotAllowed, u.Host,
+				strings.Join(owners, ", "))
+		}
+		if path == "" {
+			path = DefaultPath
+		}
+		return service.Get(ctx, u.Host, repo, ref, path)
+	}
+}
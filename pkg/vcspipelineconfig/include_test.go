@@ -0,0 +1,39 @@
+package vcspipelineconfig
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIncludeLoader(t *testing.T) {
+	svc := &fakeService{}
+	load := NewIncludeLoader(svc, []string{"Foo"})
+	u, err := url.Parse("github://foo/bar@main/.tekton/shared.yaml")
+	assert.Nil(t, err)
+	_, err = load(context.TODO(), u)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"foo", "bar", "main", ".tekton/shared.yaml"}, []string{svc.owner, svc.repo, svc.ref, svc.path})
+	u, err = url.Parse("github://foo/bar@" + testSHA)
+	assert.Nil(t, err)
+	_, err = load(context.TODO(), u)
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultPath, svc.path)
+	u, err = url.Parse("github://foo/bar/.tekton.yaml")
+	assert.Nil(t, err)
+	_, err = load(context.TODO(), u)
+	assert.NotNil(t, err)
+}
+
+func TestNewIncludeLoader_NotAllowed(t *testing.T) {
+	svc := &fakeService{}
+	load := NewIncludeLoader(svc, []string{"foo"})
+	u, err := url.Parse("github://baz/secrets@main")
+	assert.Nil(t, err)
+	_, err = load(context.TODO(), u)
+	assert.ErrorIs(t, err, pipelineconfig.ErrIncludeNotAllowed)
+	assert.Empty(t, svc.owner)
+}
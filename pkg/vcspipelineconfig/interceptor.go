@@ -2,8 +2,10 @@ package vcspipelineconfig
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfigreporter"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
 	"github.com/ElementalCognition/tekton-toolbox/pkg/triggers"
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
@@ -17,11 +19,24 @@ const (
 	ownerLoginKey = "owner"
 	repoNameKey   = "repo"
 	refParamKey   = "ref"
+	pathParamKey  = "path"
+	onMissingKey  = "onMissing"
+)
+
+const (
+	// OnMissingFail fails the interceptor when config does not exist.
+	OnMissingFail = "fail"
+	// OnMissingSkip stops processing of the trigger without an error when config does not exist.
+	OnMissingSkip = "skip"
+	// OnMissingDefaults proceeds with the config from upstream interceptors when config does not exist.
+	OnMissingDefaults = "defaults"
 )
 
 type interceptor struct {
 	service  Service
 	resolver pipelineresolver.Resolver
+	includes *pipelineconfig.IncludeResolver
+	reporter pipelineconfigreporter.Reporter
 }
 
 var _ v1beta1.InterceptorInterface = (*interceptor)(nil)
@@ -34,14 +49,49 @@ func (i *interceptor) valueOf(ctx context.Context, meta *pipelineresolver.Metada
 	return fmt.Sprint(val), nil
 }
 
+// fail includes errors of config fields in the message, and reports them, since they are mistakes of config authors
+// rather than failures of the interceptor, and the chain stops before pipeline-config-trigger could report them.
+func (i *interceptor) fail(ctx context.Context, meta *pipelineresolver.Metadata, msg string, err error) *v1beta1.InterceptorResponse {
+	var d *pipelineconfig.Diagnostic
+	if !errors.As(err, &d) {
+		return interceptors.Fail(codes.Internal, msg)
+	}
+	if i.reporter != nil {
+		rerr := i.reporter.Report(ctx, meta, err)
+		if rerr != nil {
+			logging.FromContext(ctx).Warnw("Interceptor failed to report config error", zap.Error(rerr))
+		}
+	}
+	return interceptors.Fail(codes.InvalidArgument, fmt.Sprintf("%s: %v", msg, err))
+}
+
+func (i *interceptor) defaultParam(name string) (string, bool) {
+	d, ok := i.service.(ParamDefaulter)
+	if !ok {
+		return "", false
+	}
+	val, ok := d.DefaultParams()[name]
+	return val, ok
+}
+
 func (i *interceptor) findParam(ctx context.Context, meta *pipelineresolver.Metadata, name string) (string, error) {
 	val, ok := meta.Params[name]
+	if !ok {
+		val, ok = i.defaultParam(name)
+	}
 	if !ok {
 		return "", fmt.Errorf("%s param does not exist", name)
 	}
 	return i.valueOf(ctx, meta, fmt.Sprint(val))
 }
 
+func (i *interceptor) findOptionalParam(ctx context.Context, meta *pipelineresolver.Metadata, name, def string) (string, error) {
+	if _, ok := meta.Params[name]; !ok {
+		return def, nil
+	}
+	return i.findParam(ctx, meta, name)
+}
+
 func (i *interceptor) Process(ctx context.Context, req *v1beta1.InterceptorRequest) *v1beta1.InterceptorResponse {
 	logger := logging.FromContext(ctx)
 	rw := triggers.InterceptorRequest(*req)
@@ -51,10 +101,12 @@ func (i *interceptor) Process(ctx context.Context, req *v1beta1.InterceptorReque
 		return interceptors.Fail(codes.InvalidArgument, "Request body is malformed")
 	}
 	meta := &pipelineresolver.Metadata{
-		Header:     req.Header,
-		Extensions: req.Extensions,
-		Params:     req.InterceptorParams,
-		Body:       body,
+		Header:         req.Header,
+		Extensions:     req.Extensions,
+		Params:         req.InterceptorParams,
+		Body:           body,
+		RequestURL:     rw.RequestURL(),
+		TriggerContext: req.Context,
 	}
 	owner, err := i.findParam(ctx, meta, ownerLoginKey)
 	if err != nil {
@@ -71,42 +123,112 @@ func (i *interceptor) Process(ctx context.Context, req *v1beta1.InterceptorReque
 		logger.Errorw("Interceptor failed to get `ref` parameter", zap.Error(err))
 		return interceptors.Fail(codes.InvalidArgument, "`ref` parameter is required")
 	}
+	path, err := i.findOptionalParam(ctx, meta, pathParamKey, DefaultPath)
 	if err != nil {
-		logger.Errorw("Interceptor failed to find repository", zap.Error(err))
-		return interceptors.Fail(codes.InvalidArgument, "Unable to find repository")
+		logger.Errorw("Interceptor failed to get `path` parameter", zap.Error(err))
+		return interceptors.Fail(codes.InvalidArgument, "Unable to get `path` parameter")
+	}
+	onMissing, err := i.findOptionalParam(ctx, meta, onMissingKey, OnMissingFail)
+	if err != nil {
+		logger.Errorw("Interceptor failed to get `onMissing` parameter", zap.Error(err))
+		return interceptors.Fail(codes.InvalidArgument, "Unable to get `onMissing` parameter")
+	}
+	switch onMissing {
+	case OnMissingFail, OnMissingSkip, OnMissingDefaults:
+	default:
+		logger.Errorw("Interceptor got unsupported `onMissing` parameter", zap.String("onMissing", onMissing))
+		return interceptors.Fail(codes.InvalidArgument, "`onMissing` parameter must be one of `fail`, `skip` or `defaults`")
+	}
+	prevCfg, err := i.service.Get(ctx, owner, repo, ref, path)
+	if errors.Is(err, ErrNotFound) {
+		logger.Infow("Interceptor did not find config",
+			zap.String("path", path),
+			zap.String("onMissing", onMissing),
+			zap.Error(err),
+		)
+		switch onMissing {
+		case OnMissingSkip:
+			return &v1beta1.InterceptorResponse{
+				Continue: false,
+				Status: v1beta1.Status{
+					Code:    codes.OK,
+					Message: fmt.Sprintf("Config %s does not exist", path),
+				},
+			}
+		case OnMissingDefaults:
+			prevCfg, err = &pipelineconfig.Config{}, nil
+		default:
+			return interceptors.Fail(codes.NotFound, fmt.Sprintf("Config %s does not exist", path))
+		}
 	}
-	prevCfg, err := i.service.Get(ctx, owner, repo, ref)
 	if err != nil {
 		logger.Errorw("Interceptor failed to fetch config", zap.Error(err))
-		return interceptors.Fail(codes.Internal, "Unable to fetch config")
+		return i.fail(ctx, meta, "Unable to fetch config", err)
+	}
+	prevCfg, err = i.includes.Resolve(ctx, prevCfg)
+	if err != nil {
+		logger.Errorw("Interceptor failed to resolve config includes", zap.Error(err))
+		return i.fail(ctx, meta, "Unable to resolve config includes", err)
 	}
 	nextCfg, err := rw.MergeConfig(prevCfg)
 	if err != nil {
 		logger.Errorw("Interceptor failed to merge config", zap.Error(err))
-		return interceptors.Fail(codes.Internal, "Unable to merge config")
+		return i.fail(ctx, meta, "Unable to merge config", err)
+	}
+	// Fields which do not depend on an event, eg. cycles of `needs`, are reported once config is loaded, rather than
+	// once a trigger matches.
+	err = nextCfg.Validate()
+	if err != nil {
+		logger.Errorw("Interceptor failed to validate config", zap.Error(err))
+		return i.fail(ctx, meta, "Invalid config", err)
 	}
 	buf, err := nextCfg.MarshalJSON()
 	if err != nil {
 		logger.Errorw("Interceptor failed to marshal config", zap.Error(err))
 		return interceptors.Fail(codes.Internal, "Unable to marshal config")
 	}
+	extensions := map[string]interface{}{
+		pipelineconfig.ConfigKey:     string(buf),
+		pipelineconfig.RepositoryKey: owner + "/" + repo,
+	}
+	if nextCfg.NeedsChangedFiles(pipelineresolver.WithResolver(ctx, i.resolver)) {
+		files, err := i.changedFiles(ctx, owner, repo, meta)
+		if err != nil {
+			logger.Warnw("Interceptor failed to list changed files, path filters are not applied", zap.Error(err))
+		} else if files != nil {
+			extensions[triggers.ChangedFilesKey] = files
+		}
+	}
 	return &v1beta1.InterceptorResponse{
-		Continue: true,
-		Extensions: map[string]interface{}{
-			pipelineconfig.ConfigKey: string(buf),
-		},
+		Continue:   true,
+		Extensions: extensions,
 		Status: v1beta1.Status{
 			Code: codes.OK,
 		},
 	}
 }
 
+func (i *interceptor) changedFiles(ctx context.Context, owner, repo string, meta *pipelineresolver.Metadata) ([]string, error) {
+	if _, ok := meta.Extensions[triggers.ChangedFilesKey]; ok {
+		return nil, nil
+	}
+	l, ok := i.service.(ChangedFilesLister)
+	if !ok {
+		return nil, nil
+	}
+	return l.ChangedFiles(ctx, owner, repo, meta.Body)
+}
+
 func NewInterceptor(
 	service Service,
 	resolver pipelineresolver.Resolver,
+	includes *pipelineconfig.IncludeResolver,
+	reporter pipelineconfigreporter.Reporter,
 ) v1beta1.InterceptorInterface {
 	return &interceptor{
 		service:  service,
 		resolver: resolver,
+		includes: includes,
+		reporter: reporter,
 	}
 }
@@ -0,0 +1,229 @@
+package vcspipelineconfig
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineconfig"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/pipelineresolver"
+	"github.com/ElementalCognition/tekton-toolbox/pkg/triggers"
+	"github.com/stretchr/testify/assert"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"google.golang.org/grpc/codes"
+)
+
+type fakeService struct {
+	owner, repo, ref, path string
+	cfg                    *pipelineconfig.Config
+	err                    error
+	changedFiles           []string
+}
+
+func (s *fakeService) Get(_ context.Context, owner, repo, ref, path string) (*pipelineconfig.Config, error) {
+	s.owner, s.repo, s.ref, s.path = owner, repo, ref, path
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.cfg != nil {
+		return s.cfg, nil
+	}
+	return &pipelineconfig.Config{}, nil
+}
+
+func (s *fakeService) ChangedFiles(_ context.Context, _, _ string, _ map[string]interface{}) ([]string, error) {
+	return s.changedFiles, nil
+}
+
+func (s *fakeService) DefaultParams() map[string]string {
+	return map[string]string{
+		"owner": "body.repository.owner.login",
+		"repo":  "body.repository.name",
+		"ref":   "body.after",
+	}
+}
+
+type fakeReporter struct {
+	err error
+}
+
+func (r *fakeReporter) Report(_ context.Context, _ *pipelineresolver.Metadata, err error) error {
+	r.err = err
+	return nil
+}
+
+func TestInterceptor_Process_DefaultParams(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	svc := &fakeService{}
+	i := NewInterceptor(svc, r, nil, nil)
+	res := i.Process(context.TODO(), &v1beta1.InterceptorRequest{
+		Body: `{"repository": {"name": "bar", "owner": {"login": "foo"}}, "after": "baz"}`,
+		InterceptorParams: map[string]interface{}{
+			"repo": `"qux"`,
+		},
+	})
+	assert.Equal(t, codes.OK, res.Status.Code)
+	assert.Equal(t, "foo", svc.owner)
+	assert.Equal(t, "qux", svc.repo)
+	assert.Equal(t, "baz", svc.ref)
+	assert.Equal(t, DefaultPath, svc.path)
+}
+
+func TestInterceptor_Process_Path(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	svc := &fakeService{}
+	i := NewInterceptor(svc, r, nil, nil)
+	res := i.Process(context.TODO(), &v1beta1.InterceptorRequest{
+		Body: `{"repository": {"name": "bar", "owner": {"login": "foo"}}, "after": "baz"}`,
+		InterceptorParams: map[string]interface{}{
+			"path": `body.repository.name + "/.tekton"`,
+		},
+	})
+	assert.Equal(t, codes.OK, res.Status.Code)
+	assert.Equal(t, "bar/.tekton", svc.path)
+}
+
+func TestInterceptor_Process_ChangedFiles(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	svc := &fakeService{changedFiles: []string{"services/foo/main.go"}}
+	i := NewInterceptor(svc, r, nil, nil)
+	req := &v1beta1.InterceptorRequest{
+		Body: `{"repository": {"name": "bar", "owner": {"login": "foo"}}, "after": "baz"}`,
+	}
+	res := i.Process(context.TODO(), req)
+	assert.Equal(t, codes.OK, res.Status.Code)
+	assert.NotContains(t, res.Extensions, triggers.ChangedFilesKey)
+	svc.cfg = &pipelineconfig.Config{
+		Triggers: pipelineconfig.TriggerSlice{
+			{Name: "foo", Paths: pipelineconfig.TriggerPaths{"services/foo/**"}},
+		},
+	}
+	res = i.Process(context.TODO(), req)
+	assert.Equal(t, codes.OK, res.Status.Code)
+	assert.Equal(t, []string{"services/foo/main.go"}, res.Extensions[triggers.ChangedFilesKey])
+}
+
+func TestInterceptor_Process_OnMissing(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	i := NewInterceptor(&fakeService{err: fmt.Errorf("%w: 404 Not Found", ErrNotFound)}, r, nil, nil)
+	tests := []struct {
+		onMissing string
+		cont      bool
+		code      codes.Code
+	}{
+		{"", false, codes.NotFound},
+		{OnMissingFail, false, codes.NotFound},
+		{OnMissingSkip, false, codes.OK},
+		{OnMissingDefaults, true, codes.OK},
+		{"ignore", false, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		req := &v1beta1.InterceptorRequest{
+			Body:              `{"repository": {"name": "bar", "owner": {"login": "foo"}}, "after": "baz"}`,
+			InterceptorParams: map[string]interface{}{},
+			Extensions: map[string]interface{}{
+				pipelineconfig.ConfigKey: `{"triggers": [{"name": "foo"}]}`,
+			},
+		}
+		if tt.onMissing != "" {
+			req.InterceptorParams[onMissingKey] = tt.onMissing
+		}
+		res := i.Process(context.TODO(), req)
+		assert.Equal(t, tt.cont, res.Continue, tt.onMissing)
+		assert.Equal(t, tt.code, res.Status.Code, tt.onMissing)
+		if tt.cont {
+			cfg := &pipelineconfig.Config{}
+			assert.Nil(t, cfg.UnmarshalJSON([]byte(res.Extensions[pipelineconfig.ConfigKey].(string))))
+			assert.Len(t, cfg.Triggers, 1)
+		}
+	}
+}
+
+func TestInterceptor_Process_Error(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	i := NewInterceptor(&fakeService{err: assert.AnError}, r, nil, nil)
+	res := i.Process(context.TODO(), &v1beta1.InterceptorRequest{
+		Body: `{"repository": {"name": "bar", "owner": {"login": "foo"}}, "after": "baz"}`,
+		InterceptorParams: map[string]interface{}{
+			onMissingKey: OnMissingSkip,
+		},
+	})
+	assert.Equal(t, codes.Internal, res.Status.Code)
+}
+
+func TestInterceptor_Process_Invalid(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	_, err = unmarshalConfig(DefaultPath, []byte("triggers:\n  - name: 1\n"))
+	reporter := &fakeReporter{}
+	i := NewInterceptor(&fakeService{err: err}, r, nil, reporter)
+	res := i.Process(context.TODO(), &v1beta1.InterceptorRequest{
+		Body: `{"repository": {"name": "bar", "owner": {"login": "foo"}}, "after": "baz"}`,
+	})
+	assert.Equal(t, codes.InvalidArgument, res.Status.Code)
+	assert.Contains(t, res.Status.Message, ".tekton.yaml:2:11: triggers[0].name")
+	assert.ErrorIs(t, reporter.err, err)
+
+	reporter = &fakeReporter{}
+	i = NewInterceptor(&fakeService{err: assert.AnError}, r, nil, reporter)
+	res = i.Process(context.TODO(), &v1beta1.InterceptorRequest{
+		Body: `{"repository": {"name": "bar", "owner": {"login": "foo"}}, "after": "baz"}`,
+	})
+	assert.Equal(t, codes.Internal, res.Status.Code)
+	assert.Nil(t, reporter.err)
+}
+
+func TestInterceptor_Process_InvalidNeeds(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	cfg := &pipelineconfig.Config{}
+	err = cfg.UnmarshalYAMLStrict([]byte(`
+triggers:
+  - name: main
+    pipelines:
+      - name: build
+        needs: [test]
+      - name: test
+        needs: [build]
+`))
+	assert.Nil(t, err)
+	reporter := &fakeReporter{}
+	i := NewInterceptor(&fakeService{cfg: cfg}, r, nil, reporter)
+	res := i.Process(context.TODO(), &v1beta1.InterceptorRequest{
+		Body: `{"repository": {"name": "bar", "owner": {"login": "foo"}}, "after": "baz"}`,
+	})
+	assert.Equal(t, codes.InvalidArgument, res.Status.Code)
+	assert.Contains(t, res.Status.Message, "triggers[0].pipelines[0].needs: cycle of pipelines build -> test -> build")
+	assert.NotNil(t, reporter.err)
+}
+
+func TestInterceptor_Process_Include(t *testing.T) {
+	r, err := pipelineresolver.NewCelResolver()
+	assert.Nil(t, err)
+	shared := &fakeService{cfg: &pipelineconfig.Config{
+		Triggers: pipelineconfig.TriggerSlice{{Name: "shared"}},
+	}}
+	includes := pipelineconfig.NewIncludeResolver(pipelineconfig.DefaultIncludeDepth, map[string]pipelineconfig.IncludeLoader{
+		"github": NewIncludeLoader(shared, []string{"platform"}),
+	})
+	svc := &fakeService{cfg: &pipelineconfig.Config{
+		Include:  []string{"github://platform/templates@main/pr.yaml"},
+		Triggers: pipelineconfig.TriggerSlice{{Name: "local"}},
+	}}
+	i := NewInterceptor(svc, r, includes, nil)
+	res := i.Process(context.TODO(), &v1beta1.InterceptorRequest{
+		Body: `{"repository": {"name": "bar", "owner": {"login": "foo"}}, "after": "baz"}`,
+	})
+	assert.Equal(t, codes.OK, res.Status.Code)
+	assert.Equal(t, "pr.yaml", shared.path)
+	cfg := &pipelineconfig.Config{}
+	assert.Nil(t, cfg.UnmarshalJSON([]byte(res.Extensions[pipelineconfig.ConfigKey].(string))))
+	assert.Len(t, cfg.Triggers, 2)
+	assert.Equal(t, "shared", cfg.Triggers[0].Name)
+	assert.Equal(t, "local", cfg.Triggers[1].Name)
+}
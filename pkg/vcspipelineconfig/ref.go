@@ -0,0 +1,10 @@
+package vcspipelineconfig
+
+import "regexp"
+
+var shaRegexp = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+// IsCommitSHA reports whether ref is a full SHA-1 or SHA-256 commit hash, which always points to the same tree.
+func IsCommitSHA(ref string) bool {
+	return shaRegexp.MatchString(ref)
+}
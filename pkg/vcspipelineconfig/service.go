@@ -6,5 +6,18 @@ import (
 )
 
 type Service interface {
-	Get(ctx context.Context, owner, repo, ref string) (*pipelineconfig.Config, error)
+	Get(ctx context.Context, owner, repo, ref, path string) (*pipelineconfig.Config, error)
+}
+
+// ParamDefaulter is implemented by services which know how to resolve `owner`, `repo` and `ref`
+// from their own webhook payloads when interceptor parameters are omitted.
+type ParamDefaulter interface {
+	DefaultParams() map[string]string
+}
+
+// ChangedFilesLister is implemented by services which can list files changed by a webhook event, eg. using
+// a compare API for pushes and a pull request files API for pull requests. It returns nil files when they
+// are unknown for the event.
+type ChangedFilesLister interface {
+	ChangedFiles(ctx context.Context, owner, repo string, body map[string]interface{}) ([]string, error)
 }
@@ -0,0 +1,15 @@
+package vcspipelineconfig
+
+import (
+	"net/url"
+	"strings"
+)
+
+// EscapePath escapes every segment of a config path, so it can be a part of a URL path, eg. `.tekton/build.yaml`.
+func EscapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,210 @@
+// Package vcspipelineconfigtest provides a conformance suite which every vcspipelineconfig.Service must pass.
+package vcspipelineconfigtest
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ElementalCognition/tekton-toolbox/pkg/vcspipelineconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	ConfigFile = vcspipelineconfig.DefaultPath
+	ConfigDir  = ".tekton"
+	Owner      = "tekton-toolbox-owner"
+	Repo       = "tekton-toolbox-repo"
+	Ref        = "0123456789abcdef0123456789abcdef01234567"
+	Token      = "tekton-toolbox-token"
+)
+
+const (
+	validConfig = `
+triggers:
+  - name: main
+    filter: body.ref == "refs/heads/main"
+`
+	teamConfigA = `
+triggers:
+  - name: main
+    filter: body.ref == "refs/heads/main"
+    pipelines:
+      - name: team-a
+`
+	teamConfigB = `
+triggers:
+  - name: main
+    filter: body.ref == "refs/heads/release"
+    pipelines:
+      - name: team-b
+  - name: pr
+    filter: '"pull_request" in body'
+`
+	malformedConfig = `
+triggers: [
+  - name: main
+`
+)
+
+// Repository describes a fake repository state which a backend must serve.
+// Backends must reject requests which are not authenticated with Token,
+// and respond with not found for any owner, repo, ref or path not present in Files.
+// Directories are implied by paths of Files. Backends must resolve Ref, see HasRef.
+type Repository struct {
+	Owner string
+	Repo  string
+	Ref   string
+	Token string
+	Files map[string]string
+}
+
+// Lookup returns the content of the file at path, if any.
+func (r *Repository) Lookup(owner, repo, ref, path string) (string, bool) {
+	if owner != r.Owner || repo != r.Repo || ref != r.Ref {
+		return "", false
+	}
+	content, ok := r.Files[path]
+	return content, ok
+}
+
+// HasRef reports whether ref of the repository exists.
+func (r *Repository) HasRef(owner, repo, ref string) bool {
+	return owner == r.Owner && repo == r.Repo && ref == r.Ref
+}
+
+// List returns names of files directly in a directory at dir, if any.
+func (r *Repository) List(owner, repo, ref, dir string) ([]string, bool) {
+	if owner != r.Owner || repo != r.Repo || ref != r.Ref {
+		return nil, false
+	}
+	var names []string
+	for p := range r.Files {
+		if path.Dir(p) == path.Clean(dir) {
+			names = append(names, path.Base(p))
+		}
+	}
+	sort.Strings(names)
+	return names, len(names) > 0
+}
+
+// HasDir reports whether dir contains files at any depth.
+func (r *Repository) HasDir(dir string) bool {
+	for p := range r.Files {
+		if strings.HasPrefix(p, path.Clean(dir)+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// NewService starts a fake backend serving repository and returns a service authenticated by token.
+// Backends which can not serve an arbitrary ref may replace repository.Ref with the one they serve.
+type NewService func(t *testing.T, repository *Repository, token string) vcspipelineconfig.Service
+
+func newRepository(files map[string]string) *Repository {
+	return &Repository{
+		Owner: Owner,
+		Repo:  Repo,
+		Ref:   Ref,
+		Token: Token,
+		Files: files,
+	}
+}
+
+func testGet(t *testing.T, newService NewService) {
+	r := newRepository(map[string]string{ConfigFile: validConfig})
+	svc := newService(t, r, Token)
+	cfg, err := svc.Get(context.TODO(), r.Owner, r.Repo, r.Ref, ConfigFile)
+	assert.Nil(t, err)
+	if assert.NotNil(t, cfg) {
+		assert.Len(t, cfg.Triggers, 1)
+		assert.Equal(t, "main", cfg.Triggers[0].Name)
+	}
+}
+
+func testGetPath(t *testing.T, newService NewService) {
+	r := newRepository(map[string]string{"ci/pipelines.yaml": validConfig})
+	svc := newService(t, r, Token)
+	cfg, err := svc.Get(context.TODO(), r.Owner, r.Repo, r.Ref, "ci/pipelines.yaml")
+	assert.Nil(t, err)
+	if assert.NotNil(t, cfg) {
+		assert.Len(t, cfg.Triggers, 1)
+	}
+}
+
+func testGetDir(t *testing.T, newService NewService) {
+	r := newRepository(map[string]string{
+		path.Join(ConfigDir, "a.yaml"):        teamConfigA,
+		path.Join(ConfigDir, "b.yaml"):        teamConfigB,
+		path.Join(ConfigDir, "README.md"):     "# Pipelines",
+		path.Join(ConfigDir, "nested/c.yaml"): malformedConfig,
+	})
+	svc := newService(t, r, Token)
+	cfg, err := svc.Get(context.TODO(), r.Owner, r.Repo, r.Ref, ConfigDir)
+	assert.Nil(t, err)
+	if assert.NotNil(t, cfg) && assert.Len(t, cfg.Triggers, 2) {
+		assert.Equal(t, "main", cfg.Triggers[0].Name)
+		assert.Equal(t, `body.ref == "refs/heads/release"`, string(cfg.Triggers[0].Filter))
+		if assert.Len(t, cfg.Triggers[0].Pipelines, 1) {
+			assert.Equal(t, "team-b", cfg.Triggers[0].Pipelines[0].Name)
+		}
+		assert.Equal(t, "pr", cfg.Triggers[1].Name)
+	}
+}
+
+func testMissingFile(t *testing.T, newService NewService) {
+	r := newRepository(map[string]string{})
+	svc := newService(t, r, Token)
+	_, err := svc.Get(context.TODO(), r.Owner, r.Repo, r.Ref, ConfigFile)
+	assert.ErrorIs(t, err, vcspipelineconfig.ErrNotFound)
+}
+
+func testMalformedConfig(t *testing.T, newService NewService) {
+	r := newRepository(map[string]string{ConfigFile: malformedConfig})
+	svc := newService(t, r, Token)
+	_, err := svc.Get(context.TODO(), r.Owner, r.Repo, r.Ref, ConfigFile)
+	assert.NotNil(t, err)
+}
+
+func testRefNotFound(t *testing.T, newService NewService) {
+	r := newRepository(map[string]string{ConfigFile: validConfig})
+	svc := newService(t, r, Token)
+	_, err := svc.Get(context.TODO(), r.Owner, r.Repo, "fedcba9876543210fedcba9876543210fedcba98", ConfigFile)
+	assert.NotNil(t, err)
+	assert.NotErrorIs(t, err, vcspipelineconfig.ErrNotFound)
+}
+
+func testUnauthorized(t *testing.T, newService NewService) {
+	r := newRepository(map[string]string{ConfigFile: validConfig})
+	svc := newService(t, r, "invalid-token")
+	_, err := svc.Get(context.TODO(), r.Owner, r.Repo, r.Ref, ConfigFile)
+	assert.NotNil(t, err)
+}
+
+// Run runs the conformance suite against the services created by newService.
+func Run(t *testing.T, newService NewService) {
+	t.Run("Get", func(t *testing.T) {
+		testGet(t, newService)
+	})
+	t.Run("GetPath", func(t *testing.T) {
+		testGetPath(t, newService)
+	})
+	t.Run("GetDir", func(t *testing.T) {
+		testGetDir(t, newService)
+	})
+	t.Run("MissingFile", func(t *testing.T) {
+		testMissingFile(t, newService)
+	})
+	t.Run("MalformedConfig", func(t *testing.T) {
+		testMalformedConfig(t, newService)
+	})
+	t.Run("RefNotFound", func(t *testing.T) {
+		testRefNotFound(t, newService)
+	})
+	t.Run("Unauthorized", func(t *testing.T) {
+		testUnauthorized(t, newService)
+	})
+}